@@ -0,0 +1,101 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/admin"
+	channelzservice "google.golang.org/grpc/channelz/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type peerRestriction int
+
+const (
+	// AnyPeer is a constant that improves the readability of functions that
+	// allow requests from any peer without auth.
+	AnyPeer peerRestriction = iota
+
+	// LocalPeersOnly is a constant that improves the readability of functions
+	// that only allow requests without auth from peers connected over a
+	// loopback address or a unix socket.
+	LocalPeersOnly
+)
+
+func (p peerRestriction) authorize(ctx context.Context) (context.Context, error) {
+	switch p {
+	case AnyPeer:
+		return ctx, nil
+	case LocalPeersOnly:
+		if !isLocalPeer(ctx) {
+			return nil, status.Error(codes.PermissionDenied, "only local peers are permitted")
+		}
+		return ctx, nil
+	default:
+		panic("unknown peer restriction")
+	}
+}
+
+func isLocalPeer(ctx context.Context) bool {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return false
+	}
+
+	switch addr := p.Addr.(type) {
+	case *net.TCPAddr:
+		return addr.IP.IsLoopback()
+	case *net.UnixAddr:
+		return true
+	default:
+		return p.Addr.Network() == "unix"
+	}
+}
+
+// AdminMethods matches the methods of the services registered by
+// google.golang.org/grpc/admin, which are channelz and CSDS.
+var AdminMethods = MatchMethods(
+	"/grpc.channelz.v1.Channelz/*",
+	"/envoy.service.status.v3.ClientStatusDiscoveryService/*",
+)
+
+// RegisterAuthlessChannelz registers the channelz service so that it ignores
+// any auth requirements set by github.com/grpc-ecosystem/go-grpc-middleware/auth.
+//
+// With LocalPeersOnly, requests from other peers are rejected whether or not
+// the server uses the auth middleware.
+func RegisterAuthlessChannelz(s grpc.ServiceRegistrar, p peerRestriction) {
+	channelzservice.RegisterChannelzServiceToServer(NewInterceptingRegistrar(s, AuthlessTransform(p)))
+}
+
+// RegisterAuthlessAdmin registers the services of google.golang.org/grpc/admin,
+// which are channelz and, if google.golang.org/grpc/xds is imported, CSDS, so
+// that they ignore any auth requirements set by
+// github.com/grpc-ecosystem/go-grpc-middleware/auth.
+//
+// With LocalPeersOnly, requests from other peers are rejected whether or not
+// the server uses the auth middleware.
+//
+// The returned cleanup function must be called once the server has stopped.
+func RegisterAuthlessAdmin(s grpc.ServiceRegistrar, p peerRestriction) (cleanup func(), err error) {
+	return admin.Register(NewInterceptingRegistrar(s, AuthlessTransform(p)))
+}
+
+// ExemptAdminMethodsFromAuth returns a grpc_auth.AuthFunc that skips authFunc
+// for the services registered by google.golang.org/grpc/admin.
+//
+// It is for servers on which admin.Register is called directly. The peer
+// restriction is only enforced by the server's auth middleware, so prefer
+// RegisterAuthlessAdmin, which enforces it regardless.
+func ExemptAdminMethodsFromAuth(authFunc grpc_auth.AuthFunc, p peerRestriction) grpc_auth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		if fullMethod, ok := grpc.Method(ctx); ok && AdminMethods(fullMethod) {
+			return p.authorize(ctx)
+		}
+		return authFunc(ctx)
+	}
+}
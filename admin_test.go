@@ -0,0 +1,113 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/admin"
+	channelzpb "google.golang.org/grpc/channelz/grpc_channelz_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func TestIsLocalPeer(t *testing.T) {
+	for _, tc := range []struct {
+		addr     net.Addr
+		expected bool
+	}{
+		{&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 1234}, true},
+		{&net.TCPAddr{IP: net.IPv6loopback, Port: 1234}, true},
+		{&net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}, false},
+		{&net.UnixAddr{Name: "/run/app.sock", Net: "unix"}, true},
+		{nil, false},
+	} {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tc.addr})
+		require.Equal(t, tc.expected, isLocalPeer(ctx), "%v", tc.addr)
+	}
+	require.False(t, isLocalPeer(context.Background()))
+}
+
+func TestRegisterAuthlessChannelz(t *testing.T) {
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		RegisterAuthlessChannelz(s, AnyPeer)
+	})
+	_, err := channelzpb.NewChannelzClient(conn).GetTopChannels(context.Background(), &channelzpb.GetTopChannelsRequest{})
+	require.NoError(t, err)
+
+	// Connections over bufconn are not considered local.
+	conn = serveAuthenticated(t, func(s *grpc.Server) {
+		RegisterAuthlessChannelz(s, LocalPeersOnly)
+	})
+	_, err = channelzpb.NewChannelzClient(conn).GetTopChannels(context.Background(), &channelzpb.GetTopChannelsRequest{})
+	RequireStatus(t, codes.PermissionDenied, err)
+}
+
+func TestRegisterAuthlessAdmin(t *testing.T) {
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		cleanup, err := RegisterAuthlessAdmin(s, AnyPeer)
+		require.NoError(t, err)
+		t.Cleanup(cleanup)
+	})
+	_, err := channelzpb.NewChannelzClient(conn).GetTopChannels(context.Background(), &channelzpb.GetTopChannelsRequest{})
+	require.NoError(t, err)
+
+	// The peer restriction is enforced without the auth middleware.
+	_, dial := serve(t, func(s *grpc.Server) {
+		cleanup, err := RegisterAuthlessAdmin(s, LocalPeersOnly)
+		require.NoError(t, err)
+		t.Cleanup(cleanup)
+	})
+	conn = dial(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	_, err = channelzpb.NewChannelzClient(conn).GetTopChannels(context.Background(), &channelzpb.GetTopChannelsRequest{})
+	RequireStatus(t, codes.PermissionDenied, err)
+}
+
+func TestExemptAdminMethodsFromAuth(t *testing.T) {
+	authFunc := ExemptAdminMethodsFromAuth(BearerTokenAuthFunc(StaticTokens(map[string]any{"secret": "alice"})), AnyPeer)
+
+	_, dial := serve(t, func(s *grpc.Server) {
+		cleanup, err := admin.Register(s)
+		require.NoError(t, err)
+		t.Cleanup(cleanup)
+	}, grpc.ChainUnaryInterceptor(grpc_auth.UnaryServerInterceptor(authFunc)))
+	conn := dial(grpc.WithTransportCredentials(insecure.NewCredentials()))
+
+	_, err := channelzpb.NewChannelzClient(conn).GetTopChannels(context.Background(), &channelzpb.GetTopChannelsRequest{})
+	require.NoError(t, err)
+
+	require.True(t, AdminMethods("/envoy.service.status.v3.ClientStatusDiscoveryService/FetchClientStatus"))
+	require.False(t, AdminMethods("/testpb.HelloService/HelloUnary"))
+}
+
+func TestAuthlessTransformRejectsAfterInterceptors(t *testing.T) {
+	// Server interceptors see rejected unary calls just as they see
+	// rejected streams.
+	var seen []codes.Code
+	_, dial := serve(t, func(s *grpc.Server) {
+		testpb.RegisterHelloServiceServer(NewInterceptingRegistrar(s, AuthlessTransform(LocalPeersOnly)), &testServer{})
+	},
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			resp, err := handler(ctx, req)
+			seen = append(seen, status.Code(err))
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			err := handler(srv, ss)
+			seen = append(seen, status.Code(err))
+			return err
+		}),
+	)
+	client := testpb.NewHelloServiceClient(dial(grpc.WithTransportCredentials(insecure.NewCredentials())))
+
+	RequireStatus(t, codes.PermissionDenied, sayHello(client))
+	RequireStatus(t, codes.PermissionDenied, helloStreaming(client))
+	require.Equal(t, []codes.Code{codes.PermissionDenied, codes.PermissionDenied}, seen)
+}
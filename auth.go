@@ -0,0 +1,147 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrInvalidToken is returned by a TokenValidator when the provided token is
+// not valid.
+var ErrInvalidToken = errors.New("invalid token")
+
+// TokenValidator validates bearer tokens presented by clients.
+type TokenValidator interface {
+	// ValidateToken returns the principal identified by the token or an
+	// error if the token is not valid.
+	ValidateToken(ctx context.Context, token string) (principal any, err error)
+}
+
+// TokenValidatorFunc is an adapter to allow the use of ordinary functions as a
+// TokenValidator.
+type TokenValidatorFunc func(ctx context.Context, token string) (any, error)
+
+// ValidateToken implements TokenValidator by calling f(ctx, token).
+func (f TokenValidatorFunc) ValidateToken(ctx context.Context, token string) (any, error) {
+	return f(ctx, token)
+}
+
+// StaticTokens returns a TokenValidator that accepts any of the keys of the
+// provided map, identifying the principal as the associated value.
+//
+// Tokens are compared in constant time.
+func StaticTokens(tokens map[string]any) TokenValidator {
+	type entry struct {
+		token     []byte
+		principal any
+	}
+	entries := make([]entry, 0, len(tokens))
+	for token, principal := range tokens {
+		entries = append(entries, entry{[]byte(token), principal})
+	}
+
+	return TokenValidatorFunc(func(_ context.Context, token string) (any, error) {
+		var principal any
+		found := 0
+		for _, e := range entries {
+			// Every entry is compared so that timing does not reveal
+			// which token matched.
+			if subtle.ConstantTimeCompare(e.token, []byte(token)) == 1 {
+				principal = e.principal
+				found = 1
+			}
+		}
+		if found == 0 {
+			return nil, ErrInvalidToken
+		}
+		return principal, nil
+	})
+}
+
+// BearerTokenAuthFunc returns a grpc_auth.AuthFunc that requires requests to
+// provide a standard HTTP Bearer token that is accepted by the validator.
+//
+// The principal returned by the validator is stored in the request context
+// and can be retrieved with PrincipalFromContext.
+func BearerTokenAuthFunc(validator TokenValidator) grpc_auth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		token, err := grpc_auth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, err
+		}
+
+		principal, err := validator.ValidateToken(ctx, token)
+		if err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, status.Errorf(codes.Unauthenticated, "invalid auth token: %s", err)
+		}
+
+		return ContextWithPrincipal(ctx, principal), nil
+	}
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of the context that stores the
+// authenticated principal.
+func ContextWithPrincipal(ctx context.Context, principal any) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated principal stored in the
+// context, if it is present and of type T.
+func PrincipalFromContext[T any](ctx context.Context) (T, bool) {
+	principal, ok := ctx.Value(principalKey{}).(T)
+	return principal, ok
+}
+
+// MethodMatcher reports whether a full method name, such as
+// "/package.Service/Method", matches.
+type MethodMatcher func(fullMethod string) bool
+
+// MatchMethods returns a MethodMatcher for the provided full method names.
+//
+// A name of the form "/package.Service/*" matches every method of the service.
+func MatchMethods(fullMethods ...string) MethodMatcher {
+	names := make(map[string]struct{}, len(fullMethods))
+	for _, name := range fullMethods {
+		names[name] = struct{}{}
+	}
+	return func(fullMethod string) bool {
+		for _, pattern := range methodPatterns(fullMethod) {
+			if _, ok := names[pattern]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// methodPatterns returns the names that match a full method name, in order
+// of precedence: the name itself and the wildcard for its service.
+func methodPatterns(fullMethod string) [2]string {
+	serviceName, _ := SplitMethodName(fullMethod)
+	return [2]string{fullMethod, "/" + serviceName + "/*"}
+}
+
+// ExemptMethodsFromAuth returns a grpc_auth.AuthFunc that skips authFunc for
+// methods matched by exempt.
+//
+// Unlike embedding IgnoreAuthMixin, which exempts every method of a service,
+// this allows individual methods, such as a public GetVersion, to be exempt
+// while the rest of the service requires auth.
+func ExemptMethodsFromAuth(authFunc grpc_auth.AuthFunc, exempt MethodMatcher) grpc_auth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		if fullMethod, ok := grpc.Method(ctx); ok && exempt(fullMethod) {
+			return ctx, nil
+		}
+		return authFunc(ctx)
+	}
+}
@@ -0,0 +1,96 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func withAuthorization(value string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+}
+
+func TestBearerTokenAuthFunc(t *testing.T) {
+	authFunc := BearerTokenAuthFunc(StaticTokens(map[string]any{
+		"alice-token": "alice",
+		"bob-token":   "bob",
+	}))
+
+	ctx, err := authFunc(withAuthorization("Bearer bob-token"))
+	require.NoError(t, err)
+	principal, ok := PrincipalFromContext[string](ctx)
+	require.True(t, ok)
+	require.Equal(t, "bob", principal)
+
+	_, ok = PrincipalFromContext[int](ctx)
+	require.False(t, ok)
+
+	_, err = authFunc(withAuthorization("Bearer mallory-token"))
+	RequireStatus(t, codes.Unauthenticated, err)
+
+	_, err = authFunc(withAuthorization("Basic alice-token"))
+	RequireStatus(t, codes.Unauthenticated, err)
+
+	_, err = authFunc(context.Background())
+	RequireStatus(t, codes.Unauthenticated, err)
+}
+
+func TestBearerTokenAuthFuncValidatorErrors(t *testing.T) {
+	authFunc := BearerTokenAuthFunc(TokenValidatorFunc(func(_ context.Context, token string) (any, error) {
+		if token == "forbidden" {
+			return nil, status.Error(codes.PermissionDenied, "forbidden")
+		}
+		return nil, errors.New("expired")
+	}))
+
+	_, err := authFunc(withAuthorization("Bearer forbidden"))
+	RequireStatus(t, codes.PermissionDenied, err)
+
+	_, err = authFunc(withAuthorization("Bearer other"))
+	RequireStatus(t, codes.Unauthenticated, err)
+}
+
+func TestBearerTokenAuthFuncInterceptor(t *testing.T) {
+	authFunc := BearerTokenAuthFunc(StaticTokens(map[string]any{"secret": "alice"}))
+	dial := serveHello(t, grpc.ChainUnaryInterceptor(grpc_auth.UnaryServerInterceptor(authFunc)))
+
+	client := dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("secret"))
+	require.NoError(t, sayHello(client))
+
+	client = dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("wrong"))
+	RequireStatus(t, codes.Unauthenticated, sayHello(client))
+}
+
+func TestMatchMethods(t *testing.T) {
+	matcher := MatchMethods("/testpb.HelloService/HelloUnary", "/grpc.health.v1.Health/*")
+	require.True(t, matcher("/testpb.HelloService/HelloUnary"))
+	require.False(t, matcher("/testpb.HelloService/HelloStreaming"))
+	require.True(t, matcher("/grpc.health.v1.Health/Check"))
+	require.True(t, matcher("/grpc.health.v1.Health/Watch"))
+}
+
+func TestExemptMethodsFromAuth(t *testing.T) {
+	authFunc := ExemptMethodsFromAuth(
+		BearerTokenAuthFunc(StaticTokens(map[string]any{"secret": "alice"})),
+		MatchMethods("/testpb.HelloService/HelloUnary"),
+	)
+	dial := serveHello(t,
+		grpc.ChainUnaryInterceptor(grpc_auth.UnaryServerInterceptor(authFunc)),
+		grpc.ChainStreamInterceptor(grpc_auth.StreamServerInterceptor(authFunc)),
+	)
+
+	anonymous := dial(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, sayHello(anonymous))
+	RequireStatus(t, codes.Unauthenticated, helloStreaming(anonymous))
+
+	authenticated := dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("secret"))
+	require.NoError(t, helloStreaming(authenticated))
+}
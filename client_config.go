@@ -0,0 +1,199 @@
+package grpcutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v3"
+)
+
+// ClientConfig describes how to connect to a server, so that it can be
+// provided via environment variables or a config file rather than flags.
+type ClientConfig struct {
+	// Endpoint is the URL-style endpoint accepted by NewEndpointClient, such
+	// as "grpcs://host:443".
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+
+	// Token is the Bearer token sent with every request.
+	Token string `json:"token" yaml:"token"`
+
+	// CAPaths are certificate authority chains, as paths on disk, used
+	// instead of the system-provided chain.
+	CAPaths []string `json:"ca_paths" yaml:"ca_paths"`
+
+	// SkipVerify disables verification of the server's certificate.
+	SkipVerify bool `json:"skip_verify" yaml:"skip_verify"`
+
+	// sources maps the config file key of each setting loaded from the
+	// environment to the variable it was loaded from, so that errors name
+	// the source of the offending value.
+	sources map[string]string
+}
+
+// ClientConfigError is returned when a ClientConfig setting is invalid.
+type ClientConfigError struct {
+	// Setting is the name of the offending setting: the environment variable
+	// it was loaded from, such as "APP_SKIP_VERIFY", or otherwise its config
+	// file key, such as "ca_paths".
+	Setting string
+	Err     error
+}
+
+func (e *ClientConfigError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Setting, e.Err)
+}
+
+func (e *ClientConfigError) Unwrap() error { return e.Err }
+
+// LoadClientConfigFile reads a ClientConfig from a JSON file, if the path
+// ends in ".json", or otherwise from a YAML file.
+//
+// Unknown keys are rejected so that misspelled settings are not ignored.
+func LoadClientConfigFile(path string) (*ClientConfig, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client config: %w", err)
+	}
+
+	var c ClientConfig
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&c)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&c)
+		if errors.Is(err, io.EOF) {
+			// An empty file has no settings.
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client config %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// LoadEnv overrides settings with those set in the environment, named by the
+// prefix, an underscore and the setting in upper case:
+//
+//   - PREFIX_ENDPOINT
+//   - PREFIX_TOKEN
+//   - PREFIX_CA_PATHS, separated by os.PathListSeparator
+//   - PREFIX_SKIP_VERIFY, a boolean as accepted by strconv.ParseBool
+//
+// If the prefix is empty, the settings are named without one.
+func (c *ClientConfig) LoadEnv(prefix string) error {
+	name := func(setting string) string {
+		if prefix == "" {
+			return setting
+		}
+		return prefix + "_" + setting
+	}
+
+	// Copies of the config must not share sources.
+	c.sources = maps.Clone(c.sources)
+	if c.sources == nil {
+		c.sources = make(map[string]string)
+	}
+
+	if value, ok := os.LookupEnv(name("ENDPOINT")); ok {
+		c.Endpoint = value
+		c.sources["endpoint"] = name("ENDPOINT")
+	}
+	if value, ok := os.LookupEnv(name("TOKEN")); ok {
+		c.Token = value
+		c.sources["token"] = name("TOKEN")
+	}
+	if value, ok := os.LookupEnv(name("CA_PATHS")); ok {
+		c.CAPaths = filepath.SplitList(value)
+		c.sources["ca_paths"] = name("CA_PATHS")
+	}
+	if value, ok := os.LookupEnv(name("SKIP_VERIFY")); ok {
+		skipVerify, err := strconv.ParseBool(value)
+		if err != nil {
+			return &ClientConfigError{Setting: name("SKIP_VERIFY"), Err: errors.New("must be a boolean")}
+		}
+		c.SkipVerify = skipVerify
+		c.sources["skip_verify"] = name("SKIP_VERIFY")
+	}
+	return nil
+}
+
+// setting returns the name of the source of a setting: the environment
+// variable it was loaded from, or otherwise its config file key.
+func (c ClientConfig) setting(key string) string {
+	if name, ok := c.sources[key]; ok {
+		return name
+	}
+	return key
+}
+
+// Validate returns a *ClientConfigError describing the first invalid setting,
+// including settings that contradict the endpoint, such as SkipVerify for an
+// endpoint that does not use TLS.
+func (c ClientConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &ClientConfigError{Setting: c.setting("endpoint"), Err: errors.New("must be set")}
+	}
+	if _, _, err := EndpointDialOptions(c.Endpoint); err != nil {
+		return &ClientConfigError{Setting: c.setting("endpoint"), Err: err}
+	}
+
+	secure := strings.HasPrefix(c.Endpoint, "grpcs://")
+	if c.SkipVerify && !secure {
+		return &ClientConfigError{Setting: c.setting("skip_verify"), Err: errors.New("requires a grpcs endpoint")}
+	}
+	if len(c.CAPaths) > 0 {
+		if !secure {
+			return &ClientConfigError{Setting: c.setting("ca_paths"), Err: errors.New("requires a grpcs endpoint")}
+		}
+		caFiles, err := readCertPaths(c.CAPaths...)
+		if err != nil {
+			return &ClientConfigError{Setting: c.setting("ca_paths"), Err: err}
+		}
+		if _, err := certPoolFromPEM(caFiles...); err != nil {
+			return &ClientConfigError{Setting: c.setting("ca_paths"), Err: err}
+		}
+	}
+	return nil
+}
+
+// DialOptions validates the config and returns the target and dial options
+// to pass to grpc.NewClient.
+func (c ClientConfig) DialOptions() (target string, opts []grpc.DialOption, err error) {
+	if err := c.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	var endpointOpts []EndpointOption
+	if c.SkipVerify {
+		endpointOpts = append(endpointOpts, WithEndpointVerification(SkipVerifyCA))
+	}
+	if len(c.CAPaths) > 0 {
+		endpointOpts = append(endpointOpts, WithEndpointCerts(c.CAPaths...))
+	}
+	if c.Token != "" {
+		endpointOpts = append(endpointOpts, WithEndpointBearerToken(c.Token))
+	}
+	return EndpointDialOptions(c.Endpoint, endpointOpts...)
+}
+
+// NewClient validates the config and creates a connection using it.
+func (c ClientConfig) NewClient(opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	target, dialOpts, err := c.DialOptions()
+	if err != nil {
+		return nil, err
+	}
+	return grpc.NewClient(target, append(dialOpts, opts...)...)
+}
@@ -0,0 +1,126 @@
+package grpcutil
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadClientConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	expected := &ClientConfig{
+		Endpoint:   "grpcs://example.com:443",
+		Token:      "secret",
+		CAPaths:    []string{"a.pem", "b.pem"},
+		SkipVerify: true,
+	}
+
+	yamlPath := filepath.Join(dir, "client.yaml")
+	writeFile(t, yamlPath, []byte("endpoint: grpcs://example.com:443\ntoken: secret\nca_paths: [a.pem, b.pem]\nskip_verify: true\n"))
+	c, err := LoadClientConfigFile(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	jsonPath := filepath.Join(dir, "client.json")
+	writeFile(t, jsonPath, []byte(`{"endpoint": "grpcs://example.com:443", "token": "secret", "ca_paths": ["a.pem", "b.pem"], "skip_verify": true}`))
+	c, err = LoadClientConfigFile(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, expected, c)
+
+	emptyPath := filepath.Join(dir, "empty.yaml")
+	writeFile(t, emptyPath, nil)
+	c, err = LoadClientConfigFile(emptyPath)
+	require.NoError(t, err)
+	require.Equal(t, &ClientConfig{}, c)
+
+	writeFile(t, yamlPath, []byte("endpont: grpcs://example.com\n"))
+	_, err = LoadClientConfigFile(yamlPath)
+	require.ErrorContains(t, err, "endpont")
+
+	writeFile(t, jsonPath, []byte(`{"skip_verify": "yes"}`))
+	_, err = LoadClientConfigFile(jsonPath)
+	require.ErrorContains(t, err, "skip_verify")
+}
+
+func TestClientConfigLoadEnv(t *testing.T) {
+	t.Setenv("APP_ENDPOINT", "grpc://localhost:50051")
+	t.Setenv("APP_CA_PATHS", "a.pem"+string(filepath.ListSeparator)+"b.pem")
+	t.Setenv("APP_SKIP_VERIFY", "true")
+
+	c := ClientConfig{Endpoint: "grpcs://example.com", Token: "from file"}
+	require.NoError(t, c.LoadEnv("APP"))
+	require.Equal(t, ClientConfig{
+		Endpoint:   "grpc://localhost:50051",
+		Token:      "from file",
+		CAPaths:    []string{"a.pem", "b.pem"},
+		SkipVerify: true,
+		sources: map[string]string{
+			"endpoint":    "APP_ENDPOINT",
+			"ca_paths":    "APP_CA_PATHS",
+			"skip_verify": "APP_SKIP_VERIFY",
+		},
+	}, c)
+
+	t.Setenv("APP_SKIP_VERIFY", "sometimes")
+	var configErr *ClientConfigError
+	require.True(t, errors.As(c.LoadEnv("APP"), &configErr))
+	require.Equal(t, "APP_SKIP_VERIFY", configErr.Setting)
+
+	t.Setenv("APP_SKIP_VERIFY", "1")
+	t.Setenv("TOKEN", "unprefixed")
+	require.NoError(t, c.LoadEnv("APP"))
+	require.Equal(t, "from file", c.Token)
+	t.Setenv("SKIP_VERIFY", "false")
+	require.NoError(t, c.LoadEnv(""))
+	require.Equal(t, "unprefixed", c.Token)
+}
+
+func TestClientConfigValidate(t *testing.T) {
+	ca := newTestCA(t)
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caPath, ca.certPEM)
+	invalidPath := filepath.Join(t.TempDir(), "invalid.pem")
+	writeFile(t, invalidPath, []byte("not a certificate"))
+
+	for _, tt := range []struct {
+		name    string
+		config  ClientConfig
+		setting string
+	}{
+		{"valid", ClientConfig{Endpoint: "grpcs://example.com", CAPaths: []string{caPath}, Token: "secret"}, ""},
+		{"missing endpoint", ClientConfig{}, "endpoint"},
+		{"invalid endpoint", ClientConfig{Endpoint: "example.com:443"}, "endpoint"},
+		{"missing ca", ClientConfig{Endpoint: "grpcs://example.com", CAPaths: []string{filepath.Join(t.TempDir(), "missing.pem")}}, "ca_paths"},
+		{"invalid ca", ClientConfig{Endpoint: "grpcs://example.com", CAPaths: []string{invalidPath}}, "ca_paths"},
+		{"ca without tls", ClientConfig{Endpoint: "grpc://example.com:50051", CAPaths: []string{caPath}}, "ca_paths"},
+		{"skip verify without tls", ClientConfig{Endpoint: "unix:///run/app.sock", SkipVerify: true}, "skip_verify"},
+		{"endpoint from env", ClientConfig{Endpoint: "example.com", sources: map[string]string{"endpoint": "APP_ENDPOINT"}}, "APP_ENDPOINT"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, opts, err := tt.config.DialOptions()
+			if tt.setting == "" {
+				require.NoError(t, err)
+				require.NotEmpty(t, opts)
+				return
+			}
+
+			var configErr *ClientConfigError
+			require.True(t, errors.As(err, &configErr), err)
+			require.Equal(t, tt.setting, configErr.Setting)
+			require.ErrorContains(t, err, "invalid "+tt.setting)
+		})
+	}
+}
+
+func TestClientConfigValidateEnvSource(t *testing.T) {
+	t.Setenv("APP_CA_PATHS", filepath.Join(t.TempDir(), "missing.pem"))
+
+	c := ClientConfig{Endpoint: "grpcs://example.com"}
+	require.NoError(t, c.LoadEnv("APP"))
+
+	var configErr *ClientConfigError
+	require.True(t, errors.As(c.Validate(), &configErr))
+	require.Equal(t, "APP_CA_PATHS", configErr.Setting)
+}
@@ -0,0 +1,185 @@
+package grpcutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// DescriptorSetReflection serves the gRPC reflection service from a
+// serialized FileDescriptorSet rather than the descriptors compiled into the
+// binary, such as for a proxy forwarding services it does not compile in.
+//
+// The FileDescriptorSet must include all of its imports, as produced by
+// `protoc --include_imports` or `buf build`.
+type DescriptorSetReflection struct {
+	current atomic.Pointer[descriptorSet]
+}
+
+type descriptorSet struct {
+	files    *protoregistry.Files
+	types    *dynamicpb.Types
+	services map[string]grpc.ServiceInfo
+}
+
+// NewDescriptorSetReflection creates a DescriptorSetReflection from the
+// contents of a serialized FileDescriptorSet.
+func NewDescriptorSetReflection(contents []byte) (*DescriptorSetReflection, error) {
+	r := &DescriptorSetReflection{}
+	if err := r.Update(contents); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewDescriptorSetReflectionFromFile creates a DescriptorSetReflection from a
+// serialized FileDescriptorSet provided as a path on disk.
+//
+// The file is watched for changes until the provided context is cancelled. If
+// reloading fails, the previous descriptors remain in use and the error is
+// reported via WithReloadErrorHandler.
+func NewDescriptorSetReflectionFromFile(ctx context.Context, path string, opts ...ReloadOption) (*DescriptorSetReflection, error) {
+	load := func() ([][]byte, error) {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+		}
+		return [][]byte{contents}, nil
+	}
+
+	contents, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewDescriptorSetReflection(contents[0])
+	if err != nil {
+		return nil, err
+	}
+
+	go newReloadConfig(opts).poll(ctx, contents, load, func(contents [][]byte) error {
+		return r.Update(contents[0])
+	})
+
+	return r, nil
+}
+
+// Update replaces the descriptors being served with the contents of a
+// serialized FileDescriptorSet.
+func (r *DescriptorSetReflection) Update(contents []byte) error {
+	var fds descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(contents, &fds); err != nil {
+		return fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&fds)
+	if err != nil {
+		return fmt.Errorf("failed to build descriptors: %w", err)
+	}
+
+	services := make(map[string]grpc.ServiceInfo)
+	files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len(); i++ {
+			sd := fd.Services().Get(i)
+			info := grpc.ServiceInfo{Metadata: fd.Path()}
+			for j := 0; j < sd.Methods().Len(); j++ {
+				md := sd.Methods().Get(j)
+				info.Methods = append(info.Methods, grpc.MethodInfo{
+					Name:           string(md.Name()),
+					IsClientStream: md.IsStreamingClient(),
+					IsServerStream: md.IsStreamingServer(),
+				})
+			}
+			services[string(sd.FullName())] = info
+		}
+		return true
+	})
+
+	r.current.Store(&descriptorSet{
+		files:    files,
+		types:    dynamicpb.NewTypes(files),
+		services: services,
+	})
+	return nil
+}
+
+// GetServiceInfo returns the services defined in the descriptor set.
+func (r *DescriptorSetReflection) GetServiceInfo() map[string]grpc.ServiceInfo {
+	return r.current.Load().services
+}
+
+// Register registers both the v1 and v1alpha versions of the reflection
+// service, serving the descriptor set, on the given registrar.
+//
+// To skip auth middleware, pass a registrar created by
+// NewAuthlessReflectionInterceptor.
+func (r *DescriptorSetReflection) Register(s grpc.ServiceRegistrar) {
+	opts := reflection.ServerOptions{
+		Services:           r,
+		DescriptorResolver: descriptorSetResolver{r},
+		ExtensionResolver:  descriptorSetResolver{r},
+	}
+	grpc_reflection_v1alpha.RegisterServerReflectionServer(s, reflection.NewServer(opts))
+	rpbv1.RegisterServerReflectionServer(s, reflection.NewServerV1(opts))
+}
+
+// descriptorSetResolver resolves descriptors and extensions using the most
+// recently loaded descriptor set.
+type descriptorSetResolver struct {
+	r *DescriptorSetReflection
+}
+
+var (
+	_ protodesc.Resolver           = descriptorSetResolver{}
+	_ reflection.ExtensionResolver = descriptorSetResolver{}
+)
+
+func (dr descriptorSetResolver) FindFileByPath(path string) (protoreflect.FileDescriptor, error) {
+	return dr.r.current.Load().files.FindFileByPath(path)
+}
+
+func (dr descriptorSetResolver) FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error) {
+	return dr.r.current.Load().files.FindDescriptorByName(name)
+}
+
+func (dr descriptorSetResolver) FindExtensionByName(field protoreflect.FullName) (protoreflect.ExtensionType, error) {
+	return dr.r.current.Load().types.FindExtensionByName(field)
+}
+
+func (dr descriptorSetResolver) FindExtensionByNumber(message protoreflect.FullName, field protoreflect.FieldNumber) (protoreflect.ExtensionType, error) {
+	return dr.r.current.Load().types.FindExtensionByNumber(message, field)
+}
+
+func (dr descriptorSetResolver) RangeExtensionsByMessage(message protoreflect.FullName, f func(protoreflect.ExtensionType) bool) {
+	var rangeExtensions func(xds protoreflect.ExtensionDescriptors, mds protoreflect.MessageDescriptors) bool
+	rangeExtensions = func(xds protoreflect.ExtensionDescriptors, mds protoreflect.MessageDescriptors) bool {
+		for i := 0; i < xds.Len(); i++ {
+			xd := xds.Get(i)
+			if xd.ContainingMessage().FullName() == message && !f(dynamicpb.NewExtensionType(xd)) {
+				return false
+			}
+		}
+		for i := 0; i < mds.Len(); i++ {
+			if !rangeExtensions(mds.Get(i).Extensions(), mds.Get(i).Messages()) {
+				return false
+			}
+		}
+		return true
+	}
+
+	dr.r.current.Load().files.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		return rangeExtensions(fd.Extensions(), fd.Messages())
+	})
+}
@@ -0,0 +1,75 @@
+package grpcutil
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func marshalDescriptorSet(t *testing.T, files ...protoreflect.FileDescriptor) []byte {
+	t.Helper()
+	var fds descriptorpb.FileDescriptorSet
+	for _, fd := range files {
+		fds.File = append(fds.File, protodesc.ToFileDescriptorProto(fd))
+	}
+	contents, err := proto.Marshal(&fds)
+	require.NoError(t, err)
+	return contents
+}
+
+func TestDescriptorSetReflection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptors.binpb")
+	writeFile(t, path, marshalDescriptorSet(t, testpb.File_test_proto))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	r, err := NewDescriptorSetReflectionFromFile(ctx, path, WithReloadInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	// HelloService is only known from the descriptor set.
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		r.Register(NewAuthlessReflectionInterceptor(s))
+	})
+
+	require.ElementsMatch(t, []string{"testpb.HelloService"}, listServicesV1(t, conn))
+
+	resp := reflectV1(t, conn, &rpbv1.ServerReflectionRequest{
+		MessageRequest: &rpbv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "testpb.HelloService.HelloUnary"},
+	})
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.Len(t, files, 1)
+	var fdp descriptorpb.FileDescriptorProto
+	require.NoError(t, proto.Unmarshal(files[0], &fdp))
+	require.Equal(t, "test.proto", fdp.GetName())
+
+	writeFile(t, path, marshalDescriptorSet(t, testpb.File_test_proto, healthpb.File_grpc_health_v1_health_proto))
+	require.Eventually(t, func() bool {
+		return len(listServicesV1(t, conn)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ElementsMatch(t, []string{"testpb.HelloService", "grpc.health.v1.Health"}, listServicesV1(t, conn))
+}
+
+func TestDescriptorSetReflectionInvalid(t *testing.T) {
+	_, err := NewDescriptorSetReflection([]byte("not a descriptor set"))
+	require.Error(t, err)
+
+	// Imports must be included in the set.
+	fdp := protodesc.ToFileDescriptorProto(testpb.File_test_proto)
+	fdp.Dependency = append(fdp.Dependency, "missing.proto")
+	contents, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fdp}})
+	require.NoError(t, err)
+	_, err = NewDescriptorSetReflection(contents)
+	require.Error(t, err)
+}
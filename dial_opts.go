@@ -9,41 +9,214 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"strings"
+	"sync/atomic"
 
 	"github.com/certifi/gocertifi"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 )
 
-type verification int
+// verification determines how a server's certificate is verified: one of
+// the constant modes, such as VerifyCA, or a mode returned by
+// VerifyCAWithServerName or VerifyWithCallbacks.
+type verification interface {
+	settings() verificationSettings
+}
+
+type verificationMode int
 
 const (
-	// SkipVerifyCA is a constant that improves the readability of functions
-	// with the insecureSkipVerify parameter.
-	SkipVerifyCA verification = iota
+	// SkipVerifyCA does not verify the server's certificate, so any server
+	// is trusted.
+	SkipVerifyCA verificationMode = iota
 
-	// VerifyCA is a constant that improves the readability of functions
-	// with the insecureSkipVerify parameter.
+	// VerifyCA verifies that the server's certificate was issued by a
+	// trusted certificate authority for the hostname being dialed.
 	VerifyCA
+
+	// VerifyCAIgnoringHostname verifies that the server's certificate was
+	// issued by a trusted certificate authority, but not that it is valid
+	// for the hostname being dialed, such as for servers addressed by an IP
+	// or internal name that is missing from their certificate.
+	VerifyCAIgnoringHostname
 )
 
-func (v verification) asInsecureSkipVerify() bool {
-	switch v {
+func (m verificationMode) settings() verificationSettings {
+	switch m {
 	case SkipVerifyCA:
-		return true
+		return verificationSettings{skipCA: true}
 	case VerifyCA:
-		return false
+		return verificationSettings{}
+	case VerifyCAIgnoringHostname:
+		return verificationSettings{ignoreHostname: true}
 	default:
 		panic("unknown verification")
 	}
 }
 
+// verificationSettings is the verification performed by a mode. Its fields
+// are unexported so that a mode cannot be changed once created.
+type verificationSettings struct {
+	skipCA         bool
+	ignoreHostname bool
+	serverName     string
+	callbacks      *verificationCallbacks
+}
+
+func (s verificationSettings) settings() verificationSettings { return s }
+
+type verificationCallbacks struct {
+	verifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
+	verifyConnection      func(cs tls.ConnectionState) error
+}
+
+// VerifyCAWithServerName verifies the server's certificate as VerifyCA does,
+// but for the provided hostname rather than the one being dialed.
+func VerifyCAWithServerName(serverName string) verification {
+	return verificationSettings{serverName: serverName}
+}
+
+// VerifyWithCallbacks verifies the server's certificate as base does and then
+// calls the callbacks, either of which may be nil, as the
+// VerifyPeerCertificate and VerifyConnection fields of tls.Config would be.
+//
+// With VerifyCAIgnoringHostname as base, verifiedChains and the
+// VerifiedChains of the connection state contain the chains verified without
+// checking the hostname. With SkipVerifyCA as base, the callbacks alone are
+// responsible for verifying the server.
+//
+// As with tls.Config, verifyPeerCertificate is not called for resumed TLS
+// sessions, so checks that must apply to every connection belong in
+// verifyConnection.
+func VerifyWithCallbacks(
+	base verification,
+	verifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error,
+	verifyConnection func(cs tls.ConnectionState) error,
+) verification {
+	settings := base.settings()
+	var existing verificationCallbacks
+	if settings.callbacks != nil {
+		existing = *settings.callbacks
+	}
+
+	callbacks := existing
+	if verifyPeerCertificate != nil {
+		callbacks.verifyPeerCertificate = verifyPeerCertificate
+		if existing.verifyPeerCertificate != nil {
+			callbacks.verifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+				if err := existing.verifyPeerCertificate(rawCerts, verifiedChains); err != nil {
+					return err
+				}
+				return verifyPeerCertificate(rawCerts, verifiedChains)
+			}
+		}
+	}
+	if verifyConnection != nil {
+		callbacks.verifyConnection = verifyConnection
+		if existing.verifyConnection != nil {
+			callbacks.verifyConnection = func(cs tls.ConnectionState) error {
+				if err := existing.verifyConnection(cs); err != nil {
+					return err
+				}
+				return verifyConnection(cs)
+			}
+		}
+	}
+
+	settings.callbacks = &callbacks
+	return settings
+}
+
+// tlsConfig returns a tls.Config that verifies servers as v does using the
+// certificate authorities in roots.
+func tlsConfig(v verification, roots *x509.CertPool) *tls.Config {
+	s := v.settings()
+	config := &tls.Config{
+		RootCAs:            roots,
+		ServerName:         s.serverName,
+		InsecureSkipVerify: s.skipCA || s.ignoreHostname, // nolint:gosec
+	}
+	if s.callbacks != nil {
+		config.VerifyPeerCertificate = s.callbacks.verifyPeerCertificate
+		config.VerifyConnection = s.callbacks.verifyConnection
+	}
+
+	if s.ignoreHostname && !s.skipCA {
+		// Verification is skipped by the handshake, so the chain is verified
+		// here without the hostname. VerifyConnection is called for every
+		// connection, including resumed sessions, so it is verified there.
+		nextConnection := config.VerifyConnection
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			chains, err := verifyCertificates(cs.PeerCertificates, roots)
+			if err != nil {
+				return err
+			}
+			if nextConnection != nil {
+				cs.VerifiedChains = chains
+				return nextConnection(cs)
+			}
+			return nil
+		}
+
+		if next := config.VerifyPeerCertificate; next != nil {
+			config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				chains, err := verifyChain(rawCerts, roots)
+				if err != nil {
+					return err
+				}
+				return next(rawCerts, chains)
+			}
+		}
+	}
+	return config
+}
+
+// verifyChain verifies the certificates presented by a server against roots,
+// or the system-provided chain if roots is nil, without checking the
+// hostname.
+func verifyChain(rawCerts [][]byte, roots *x509.CertPool) ([][]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+	return verifyCertificates(certs, roots)
+}
+
+func verifyCertificates(certs []*x509.Certificate, roots *x509.CertPool) ([][]*x509.Certificate, error) {
+	if len(certs) == 0 {
+		return nil, errors.New("server did not present a certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	return certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+}
+
 // WithSystemCerts returns a grpc.DialOption that uses the system-provided
 // certificate authority chain to verify the connection.
 //
 // If one cannot be found, this falls back to using a vendored version of
 // Mozilla's collection of root certificate authorities.
 func WithSystemCerts(v verification) (grpc.DialOption, error) {
+	certPool, err := systemCertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig(v, certPool))), nil
+}
+
+func systemCertPool() (*x509.CertPool, error) {
 	certPool, err := x509.SystemCertPool()
 	if err != nil {
 		// Fall back to Mozilla collection of root CAs.
@@ -53,11 +226,7 @@ func WithSystemCerts(v verification) (grpc.DialOption, error) {
 			return nil, fmt.Errorf("gocertifi returned an error: %w", err)
 		}
 	}
-
-	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
-		RootCAs:            certPool,
-		InsecureSkipVerify: v.asInsecureSkipVerify(), // nolint
-	})), nil
+	return certPool, nil
 }
 
 func forEachFileContents(dirPath string, fn func(contents []byte)) error {
@@ -82,6 +251,54 @@ func forEachFileContents(dirPath string, fn func(contents []byte)) error {
 //
 // If the path is a directory, all files are loaded.
 func WithCustomCerts(v verification, certPaths ...string) (grpc.DialOption, error) {
+	caFiles, err := readCertPaths(certPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return WithCustomCertBytes(v, caFiles...)
+}
+
+// WithReloadingCustomCerts returns a grpc.DialOption for requiring TLS that is
+// authenticated using a certificate authority chain provided as a path on disk.
+//
+// Unlike WithCustomCerts, the paths are watched for changes until the provided
+// context is cancelled, and new handshakes use the most recently loaded
+// certificate authorities. If reloading fails, the previous certificate
+// authorities remain in use and the error is reported via
+// WithReloadErrorHandler.
+func WithReloadingCustomCerts(ctx context.Context, v verification, certPaths []string, opts ...ReloadOption) (grpc.DialOption, error) {
+	caFiles, err := readCertPaths(certPaths...)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool, err := certPoolFromPEM(caFiles...)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := newReloadingTLSCreds(tlsConfig(v, certPool))
+
+	go newReloadConfig(opts).poll(ctx, caFiles, func() ([][]byte, error) {
+		return readCertPaths(certPaths...)
+	}, func(caFiles [][]byte) error {
+		certPool, err := certPoolFromPEM(caFiles...)
+		if err != nil {
+			return err
+		}
+
+		creds.config.Store(tlsConfig(v, certPool))
+		return nil
+	})
+
+	return grpc.WithTransportCredentials(creds), nil
+}
+
+// readCertPaths returns the contents of every file at the provided paths.
+//
+// If a path is a directory, all files within it are read.
+func readCertPaths(certPaths ...string) ([][]byte, error) {
 	var caFiles [][]byte
 	for _, certPath := range certPaths {
 		fi, err := os.Stat(certPath)
@@ -103,24 +320,137 @@ func WithCustomCerts(v verification, certPaths ...string) (grpc.DialOption, erro
 			caFiles = append(caFiles, contents)
 		}
 	}
+	return caFiles, nil
+}
 
-	return WithCustomCertBytes(v, caFiles...)
+// readCAPaths is readCertPaths for certificate authorities that replace a
+// default, which fails if no certificates are found rather than letting the
+// default be used instead.
+func readCAPaths(certPaths ...string) ([][]byte, error) {
+	caFiles, err := readCertPaths(certPaths...)
+	if err != nil {
+		return nil, err
+	}
+	if len(caFiles) == 0 {
+		return nil, fmt.Errorf("no certificates found in %s", strings.Join(certPaths, ", "))
+	}
+	return caFiles, nil
 }
 
-// WithCustomCertBytes returns a grpc.DialOption for requiring TLS that is
-// authenticated using a certificate authority chain provided in bytes.
-func WithCustomCertBytes(v verification, certsContents ...[]byte) (grpc.DialOption, error) {
+func certPoolFromPEM(certsContents ...[]byte) (*x509.CertPool, error) {
 	certPool := x509.NewCertPool()
 	for _, certContents := range certsContents {
 		if ok := certPool.AppendCertsFromPEM(certContents); !ok {
 			return nil, errors.New("failed to append certs from CA PEM")
 		}
 	}
+	return certPool, nil
+}
 
-	return grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
-		RootCAs:            certPool,
-		InsecureSkipVerify: v.asInsecureSkipVerify(), // nolint:gosec
-	})), nil
+// WithCustomCertBytes returns a grpc.DialOption for requiring TLS that is
+// authenticated using a certificate authority chain provided in bytes.
+func WithCustomCertBytes(v verification, certsContents ...[]byte) (grpc.DialOption, error) {
+	certPool, err := certPoolFromPEM(certsContents...)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig(v, certPool))), nil
+}
+
+// WithClientCertificate returns a grpc.DialOption for requiring mutual TLS
+// that presents the certificate and key provided as paths on disk.
+//
+// The server is authenticated using the certificate authority chain provided
+// as caPaths, or the system-provided chain if caPaths is empty.
+//
+// The certificate and key are watched for changes until the provided context
+// is cancelled, and new handshakes present the most recently loaded keypair.
+// If reloading fails, the previous keypair remains in use and the error is
+// reported via WithReloadErrorHandler.
+func WithClientCertificate(ctx context.Context, v verification, certPath, keyPath string, caPaths []string, opts ...ReloadOption) (grpc.DialOption, error) {
+	var caFiles [][]byte
+	if len(caPaths) > 0 {
+		var err error
+		caFiles, err = readCAPaths(caPaths...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pairFiles, err := readKeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, err := tls.X509KeyPair(pairFiles[0], pairFiles[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	var current atomic.Pointer[tls.Certificate]
+	current.Store(&pair)
+
+	go newReloadConfig(opts).poll(ctx, pairFiles, func() ([][]byte, error) {
+		return readKeyPair(certPath, keyPath)
+	}, func(pairFiles [][]byte) error {
+		pair, err := tls.X509KeyPair(pairFiles[0], pairFiles[1])
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		current.Store(&pair)
+		return nil
+	})
+
+	return withClientCertificate(v, func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return current.Load(), nil
+	}, caFiles...)
+}
+
+// WithClientCertificateBytes returns a grpc.DialOption for requiring mutual
+// TLS that presents the certificate and key provided in bytes.
+//
+// The server is authenticated using the certificate authority chain provided
+// as caCerts, or the system-provided chain if caCerts is empty.
+func WithClientCertificateBytes(v verification, certPEM, keyPEM []byte, caCerts ...[]byte) (grpc.DialOption, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	return withClientCertificate(v, func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return &pair, nil
+	}, caCerts...)
+}
+
+func withClientCertificate(v verification, getCert func(*tls.CertificateRequestInfo) (*tls.Certificate, error), caCerts ...[]byte) (grpc.DialOption, error) {
+	var certPool *x509.CertPool
+	var err error
+	if len(caCerts) > 0 {
+		certPool, err = certPoolFromPEM(caCerts...)
+	} else {
+		certPool, err = systemCertPool()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := tlsConfig(v, certPool)
+	config.GetClientCertificate = getCert
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+// readKeyPair returns the contents of a certificate and key on disk.
+func readKeyPair(certPath, keyPath string) ([][]byte, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	return [][]byte{certPEM, keyPEM}, nil
 }
 
 type secureMetadataCreds map[string]string
@@ -150,3 +480,74 @@ func (c insecureMetadataCreds) GetRequestMetadata(_ context.Context, _ ...string
 func WithInsecureBearerToken(token string) grpc.DialOption {
 	return grpc.WithPerRPCCredentials(insecureMetadataCreds{"authorization": "Bearer " + token})
 }
+
+// WithBearerTokenFile returns a grpc.DialOption that adds a standard HTTP
+// Bearer token read from a path on disk to all requests sent from a client.
+//
+// The file is watched for changes until the provided context is cancelled, so
+// that tokens rotated in place, such as Kubernetes projected service account
+// tokens, are picked up by subsequent requests. If reloading fails, the
+// previous token remains in use and the error is reported via
+// WithReloadErrorHandler.
+func WithBearerTokenFile(ctx context.Context, tokenPath string, opts ...ReloadOption) (grpc.DialOption, error) {
+	creds, err := newFileTokenCreds(ctx, tokenPath, true, opts)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithPerRPCCredentials(creds), nil
+}
+
+// WithInsecureBearerTokenFile returns a grpc.DialOption that adds a standard
+// HTTP Bearer token read from a path on disk to all requests sent from an
+// insecure client.
+//
+// Must be used in conjunction with `insecure.NewCredentials()`.
+func WithInsecureBearerTokenFile(ctx context.Context, tokenPath string, opts ...ReloadOption) (grpc.DialOption, error) {
+	creds, err := newFileTokenCreds(ctx, tokenPath, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithPerRPCCredentials(creds), nil
+}
+
+type fileTokenCreds struct {
+	token                    atomic.Pointer[string]
+	requireTransportSecurity bool
+}
+
+func newFileTokenCreds(ctx context.Context, tokenPath string, requireTransportSecurity bool, opts []ReloadOption) (*fileTokenCreds, error) {
+	load := func() ([][]byte, error) {
+		contents, err := os.ReadFile(tokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token: %w", err)
+		}
+		return [][]byte{contents}, nil
+	}
+
+	creds := &fileTokenCreds{requireTransportSecurity: requireTransportSecurity}
+	apply := func(contents [][]byte) error {
+		token := strings.TrimSpace(string(contents[0]))
+		if token == "" {
+			return fmt.Errorf("token file %s is empty", tokenPath)
+		}
+		creds.token.Store(&token)
+		return nil
+	}
+
+	contents, err := load()
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(contents); err != nil {
+		return nil, err
+	}
+
+	go newReloadConfig(opts).poll(ctx, contents, load, apply)
+
+	return creds, nil
+}
+
+func (c *fileTokenCreds) RequireTransportSecurity() bool { return c.requireTransportSecurity }
+func (c *fileTokenCreds) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + *c.token.Load()}, nil
+}
@@ -1,7 +1,9 @@
 package grpcutil_test
 
 import (
+	"context"
 	"log"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -21,6 +23,20 @@ func ExampleWithSystemCerts() {
 	}
 }
 
+func ExampleVerifyCAIgnoringHostname() {
+	// Servers addressed by IP are verified against the CA without requiring
+	// the IP to be listed in their certificate.
+	withCustomCerts, err := grpcutil.WithCustomCerts(grpcutil.VerifyCAIgnoringHostname, "/etc/ssl/internal-ca.pem")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = grpc.NewClient("10.0.0.12:50051", withCustomCerts)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
 func ExampleWithBearerToken() {
 	withSystemCerts, err := grpcutil.WithSystemCerts(grpcutil.VerifyCA)
 	if err != nil {
@@ -47,3 +63,24 @@ func ExampleWithInsecureBearerToken() {
 		log.Fatal(err)
 	}
 }
+
+func ExampleWithTokenSource() {
+	withSystemCerts, err := grpcutil.WithSystemCerts(grpcutil.VerifyCA)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = grpc.NewClient(
+		"grpc.authzed.com:443",
+		withSystemCerts,
+		grpcutil.WithTokenSource(grpcutil.TokenSourceFunc(func(context.Context) (grpcutil.Token, error) {
+			return grpcutil.Token{
+				Value:  "t_your_token_here_1234567deadbeef",
+				Expiry: time.Now().Add(time.Hour),
+			}, nil
+		})),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
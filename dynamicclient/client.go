@@ -0,0 +1,401 @@
+// Package dynamicclient invokes gRPC methods by name, without generated
+// stubs, using descriptors fetched via server reflection.
+//
+// Requests and responses are represented as protobuf JSON.
+package dynamicclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/authzed/grpcutil"
+)
+
+// reflectionMethods are the reflection services queried, in order of
+// preference. v1alpha is wire compatible with v1, so both are spoken using
+// the v1 messages.
+var reflectionMethods = []string{
+	rpbv1.ServerReflection_ServerReflectionInfo_FullMethodName,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo",
+}
+
+// Client invokes methods on a connection by their full name, such as
+// "/package.Service/Method".
+//
+// Descriptors are fetched from the server's reflection service the first time
+// a service is used and cached for the lifetime of the Client.
+type Client struct {
+	conn grpc.ClientConnInterface
+
+	// mu serializes fetching descriptors; files is replaced rather than
+	// modified so that it can be read without holding mu.
+	mu    sync.Mutex
+	files atomic.Pointer[protoregistry.Files]
+}
+
+// New creates a Client that invokes methods over the provided connection,
+// such as a *grpc.ClientConn configured with grpcutil's dial options.
+func New(conn grpc.ClientConnInterface) *Client {
+	c := &Client{conn: conn}
+	c.files.Store(new(protoregistry.Files))
+	return c
+}
+
+// Method returns the descriptor of the method with the given full name.
+func (c *Client) Method(ctx context.Context, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	m, err := c.method(ctx, fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return m.desc, nil
+}
+
+// Invoke calls a unary method with a JSON request and returns the JSON
+// response.
+func (c *Client) Invoke(ctx context.Context, fullMethod string, request []byte, opts ...grpc.CallOption) ([]byte, error) {
+	m, err := c.method(ctx, fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if m.desc.IsStreamingClient() || m.desc.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s is streaming", m.fullMethod)
+	}
+
+	req, err := m.unmarshalRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	resp := dynamicpb.NewMessage(m.desc.Output())
+	if err := c.conn.Invoke(ctx, m.fullMethod, req, resp, opts...); err != nil {
+		return nil, err
+	}
+	return m.marshalResponse(resp)
+}
+
+// InvokeStream calls a method of any kind by sending each of the JSON
+// requests, closing the send direction and returning every JSON response.
+func (c *Client) InvokeStream(ctx context.Context, fullMethod string, requests [][]byte, opts ...grpc.CallOption) ([][]byte, error) {
+	stream, err := c.NewStream(ctx, fullMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, request := range requests {
+		if err := stream.Send(request); err != nil {
+			// The server's status is reported by Recv.
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+
+	var responses [][]byte
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return responses, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+}
+
+// NewStream starts a call to a method of any kind.
+//
+// For methods that are not client streaming, exactly one request must be sent
+// before calling CloseSend.
+func (c *Client) NewStream(ctx context.Context, fullMethod string, opts ...grpc.CallOption) (*Stream, error) {
+	m, err := c.method(ctx, fullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := c.conn.NewStream(ctx, &grpc.StreamDesc{
+		StreamName:    string(m.desc.Name()),
+		ClientStreams: m.desc.IsStreamingClient(),
+		ServerStreams: m.desc.IsStreamingServer(),
+	}, m.fullMethod, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{stream: stream, method: m}, nil
+}
+
+// Stream is an in-progress call started by NewStream.
+type Stream struct {
+	stream grpc.ClientStream
+	method *method
+	done   bool
+}
+
+// Send sends a JSON request.
+func (s *Stream) Send(request []byte) error {
+	req, err := s.method.unmarshalRequest(request)
+	if err != nil {
+		return err
+	}
+	return s.stream.SendMsg(req)
+}
+
+// CloseSend closes the send direction of the stream.
+func (s *Stream) CloseSend() error {
+	return s.stream.CloseSend()
+}
+
+// Recv receives a JSON response, returning io.EOF once the call has completed
+// successfully.
+func (s *Stream) Recv() ([]byte, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	resp := dynamicpb.NewMessage(s.method.desc.Output())
+	if err := s.stream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	// Methods that are not server streaming have a single response.
+	s.done = !s.method.desc.IsStreamingServer()
+	return s.method.marshalResponse(resp)
+}
+
+// Header returns the header metadata received from the server.
+func (s *Stream) Header() (map[string][]string, error) {
+	return s.stream.Header()
+}
+
+// Trailer returns the trailer metadata received from the server, once Recv
+// has returned io.EOF or an error.
+func (s *Stream) Trailer() map[string][]string {
+	return s.stream.Trailer()
+}
+
+type method struct {
+	fullMethod string
+	desc       protoreflect.MethodDescriptor
+	types      *dynamicpb.Types
+}
+
+func (m *method) unmarshalRequest(request []byte) (proto.Message, error) {
+	req := dynamicpb.NewMessage(m.desc.Input())
+	if err := (protojson.UnmarshalOptions{Resolver: m.types}).Unmarshal(request, req); err != nil {
+		return nil, fmt.Errorf("failed to parse request for %s: %w", m.fullMethod, err)
+	}
+	return req, nil
+}
+
+func (m *method) marshalResponse(resp proto.Message) ([]byte, error) {
+	return protojson.MarshalOptions{Resolver: m.types}.Marshal(resp)
+}
+
+func (c *Client) method(ctx context.Context, fullMethod string) (*method, error) {
+	if strings.Count(strings.TrimPrefix(fullMethod, "/"), "/") != 1 {
+		return nil, fmt.Errorf("invalid method name %q", fullMethod)
+	}
+	serviceName, methodName := grpcutil.SplitMethodName(fullMethod)
+
+	files, err := c.filesWithSymbol(ctx, protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := files.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", serviceName, err)
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", serviceName)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(methodName))
+	if md == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+
+	return &method{
+		fullMethod: "/" + serviceName + "/" + methodName,
+		desc:       md,
+		types:      dynamicpb.NewTypes(files),
+	}, nil
+}
+
+// filesWithSymbol returns the cached files, first fetching the file that
+// defines the symbol if it is not present.
+func (c *Client) filesWithSymbol(ctx context.Context, symbol protoreflect.FullName) (*protoregistry.Files, error) {
+	if files := c.files.Load(); hasDescriptor(files, symbol) {
+		return files, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Another call may have fetched it while waiting for the lock.
+	current := c.files.Load()
+	if hasDescriptor(current, symbol) {
+		return current, nil
+	}
+
+	fetched, err := c.fetchFiles(ctx, current, string(symbol))
+	if err != nil {
+		return nil, err
+	}
+
+	files := new(protoregistry.Files)
+	var registerErr error
+	current.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+		registerErr = files.RegisterFile(fd)
+		return registerErr == nil
+	})
+	if registerErr != nil {
+		return nil, registerErr
+	}
+
+	var register func(name string) error
+	register = func(name string) error {
+		if _, err := files.FindFileByPath(name); err == nil {
+			return nil
+		}
+		fdp, ok := fetched[name]
+		if !ok {
+			return fmt.Errorf("server did not provide descriptor for %s", name)
+		}
+		for _, dep := range fdp.GetDependency() {
+			if err := register(dep); err != nil {
+				return err
+			}
+		}
+		fd, err := protodesc.NewFile(fdp, files)
+		if err != nil {
+			return fmt.Errorf("invalid descriptor for %s: %w", name, err)
+		}
+		return files.RegisterFile(fd)
+	}
+	for name := range fetched {
+		if err := register(name); err != nil {
+			return nil, err
+		}
+	}
+
+	c.files.Store(files)
+	return files, nil
+}
+
+func hasDescriptor(files *protoregistry.Files, name protoreflect.FullName) bool {
+	_, err := files.FindDescriptorByName(name)
+	return err == nil
+}
+
+// fetchFiles fetches the file defining the symbol and any of its transitive
+// dependencies that are not already known.
+func (c *Client) fetchFiles(ctx context.Context, known *protoregistry.Files, symbol string) (map[string]*descriptorpb.FileDescriptorProto, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, resp, err := c.openReflection(ctx, &rpbv1.ServerReflectionRequest{
+		MessageRequest: &rpbv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	fetched := make(map[string]*descriptorpb.FileDescriptorProto)
+	requested := ""
+	for {
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return nil, status.Errorf(codes.Code(errResp.GetErrorCode()), "reflection failed: %s", errResp.GetErrorMessage())
+		}
+		for _, encoded := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			var fdp descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(encoded, &fdp); err != nil {
+				return nil, fmt.Errorf("invalid descriptor from reflection: %w", err)
+			}
+			fetched[fdp.GetName()] = &fdp
+		}
+		if _, ok := fetched[requested]; requested != "" && !ok {
+			// Requesting it again would never make progress.
+			return nil, fmt.Errorf("server did not provide descriptor for dependency %s", requested)
+		}
+
+		// The server may omit dependencies it has already sent on the
+		// stream or that are well known, so request any still missing.
+		missing := ""
+		for _, fdp := range fetched {
+			for _, dep := range fdp.GetDependency() {
+				if _, ok := fetched[dep]; ok {
+					continue
+				}
+				if _, err := known.FindFileByPath(dep); err == nil {
+					continue
+				}
+				missing = dep
+			}
+		}
+		if missing == "" {
+			return fetched, nil
+		}
+
+		requested = missing
+		if err := stream.SendMsg(&rpbv1.ServerReflectionRequest{
+			MessageRequest: &rpbv1.ServerReflectionRequest_FileByFilename{FileByFilename: missing},
+		}); err != nil {
+			return nil, err
+		}
+		resp = new(rpbv1.ServerReflectionResponse)
+		if err := stream.RecvMsg(resp); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// openReflection sends the first request over a reflection stream, falling
+// back to older versions of the reflection service if the server does not
+// implement newer ones.
+func (c *Client) openReflection(ctx context.Context, req *rpbv1.ServerReflectionRequest) (grpc.ClientStream, *rpbv1.ServerReflectionResponse, error) {
+	desc := &grpc.StreamDesc{
+		StreamName:    "ServerReflectionInfo",
+		ClientStreams: true,
+		ServerStreams: true,
+	}
+
+	var err error
+	for _, reflectionMethod := range reflectionMethods {
+		var stream grpc.ClientStream
+		stream, err = c.conn.NewStream(ctx, desc, reflectionMethod)
+		if err != nil {
+			return nil, nil, err
+		}
+		// A failed send reports io.EOF, leaving the status to RecvMsg.
+		if err = stream.SendMsg(req); err == nil || errors.Is(err, io.EOF) {
+			resp := new(rpbv1.ServerReflectionResponse)
+			if err = stream.RecvMsg(resp); err == nil {
+				return stream, resp, nil
+			}
+		}
+		if status.Code(err) != codes.Unimplemented {
+			break
+		}
+	}
+	return nil, nil, fmt.Errorf("failed to query server reflection: %w", err)
+}
@@ -0,0 +1,217 @@
+package dynamicclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/reflection"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/authzed/grpcutil"
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+type echoServer struct {
+	testpb.UnimplementedHelloServiceServer
+}
+
+func (echoServer) HelloUnary(_ context.Context, req *testpb.HelloRequest) (*testpb.HelloResponse, error) {
+	if req.Message == "fail" {
+		return nil, status.Error(codes.InvalidArgument, "asked to fail")
+	}
+	return &testpb.HelloResponse{Message: req.Message}, nil
+}
+
+func (echoServer) HelloStreaming(req *testpb.HelloRequest, stream testpb.HelloService_HelloStreamingServer) error {
+	for _, word := range strings.Fields(req.Message) {
+		if err := stream.Send(&testpb.HelloResponse{Message: word}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (echoServer) HelloClientStreaming(stream testpb.HelloService_HelloClientStreamingServer) error {
+	var words []string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&testpb.HelloResponse{Message: strings.Join(words, " ")})
+		}
+		if err != nil {
+			return err
+		}
+		words = append(words, req.Message)
+	}
+}
+
+func (echoServer) HelloBidiStreaming(stream testpb.HelloService_HelloBidiStreamingServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&testpb.HelloResponse{Message: strings.ToUpper(req.Message)}); err != nil {
+			return err
+		}
+	}
+}
+
+func serve(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	testpb.RegisterHelloServiceServer(s, echoServer{})
+	register(s)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///localhost", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestInvoke(t *testing.T) {
+	client := New(serve(t, func(s *grpc.Server) { reflection.Register(s) }))
+	ctx := context.Background()
+
+	resp, err := client.Invoke(ctx, "/testpb.HelloService/HelloUnary", []byte(`{"message": "hi"}`))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"message": "hi"}`, string(resp))
+
+	_, err = client.Invoke(ctx, "/testpb.HelloService/HelloUnary", []byte(`{"message": "fail"}`))
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = client.Invoke(ctx, "/testpb.HelloService/HelloUnary", []byte(`{"unknown": 1}`))
+	require.ErrorContains(t, err, "failed to parse request")
+
+	_, err = client.Invoke(ctx, "/testpb.HelloService/HelloStreaming", []byte(`{}`))
+	require.ErrorContains(t, err, "is streaming")
+
+	_, err = client.Invoke(ctx, "/testpb.HelloService/Missing", []byte(`{}`))
+	require.ErrorContains(t, err, "method Missing not found")
+
+	_, err = client.Invoke(ctx, "/testpb.Missing/Method", []byte(`{}`))
+	grpcutil.RequireStatus(t, codes.NotFound, err)
+
+	_, err = client.Invoke(ctx, "testpb.HelloService.HelloUnary", []byte(`{}`))
+	require.ErrorContains(t, err, "invalid method name")
+}
+
+func TestInvokeStream(t *testing.T) {
+	client := New(serve(t, func(s *grpc.Server) { reflection.Register(s) }))
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		method   string
+		requests []string
+		expected []string
+	}{
+		{"HelloUnary", []string{`{"message": "hi"}`}, []string{`{"message": "hi"}`}},
+		{"HelloStreaming", []string{`{"message": "hello there"}`}, []string{`{"message": "hello"}`, `{"message": "there"}`}},
+		{"HelloClientStreaming", []string{`{"message": "hello"}`, `{"message": "there"}`}, []string{`{"message": "hello there"}`}},
+		{"HelloBidiStreaming", []string{`{"message": "a"}`, `{"message": "b"}`}, []string{`{"message": "A"}`, `{"message": "B"}`}},
+	} {
+		t.Run(tt.method, func(t *testing.T) {
+			requests := make([][]byte, len(tt.requests))
+			for i, request := range tt.requests {
+				requests[i] = []byte(request)
+			}
+
+			responses, err := client.InvokeStream(ctx, "/testpb.HelloService/"+tt.method, requests)
+			require.NoError(t, err)
+			require.Len(t, responses, len(tt.expected))
+			for i, expected := range tt.expected {
+				require.JSONEq(t, expected, string(responses[i]))
+			}
+		})
+	}
+}
+
+func TestReflectionV1AlphaFallback(t *testing.T) {
+	client := New(serve(t, func(s *grpc.Server) {
+		grpc_reflection_v1alpha.RegisterServerReflectionServer(s, reflection.NewServer(reflection.ServerOptions{Services: s}))
+	}))
+
+	md, err := client.Method(context.Background(), "/testpb.HelloService/HelloBidiStreaming")
+	require.NoError(t, err)
+	require.True(t, md.IsStreamingClient())
+	require.True(t, md.IsStreamingServer())
+}
+
+func TestReflectionUnavailable(t *testing.T) {
+	client := New(serve(t, func(*grpc.Server) {}))
+
+	_, err := client.Invoke(context.Background(), "/testpb.HelloService/HelloUnary", []byte(`{}`))
+	grpcutil.RequireStatus(t, codes.Unimplemented, err)
+}
+
+// omittingReflectionServer serves a file whose dependency it never provides.
+type omittingReflectionServer struct {
+	rpbv1.UnimplementedServerReflectionServer
+}
+
+func (omittingReflectionServer) ServerReflectionInfo(stream rpbv1.ServerReflection_ServerReflectionInfoServer) error {
+	encoded, err := proto.Marshal(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("omitting.proto"),
+		Package:    proto.String("omitting"),
+		Dependency: []string{"missing.proto"},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name: proto.String("Service"),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		resp := &rpbv1.ServerReflectionResponse{
+			MessageResponse: &rpbv1.ServerReflectionResponse_FileDescriptorResponse{
+				FileDescriptorResponse: &rpbv1.FileDescriptorResponse{},
+			},
+		}
+		if req.GetFileContainingSymbol() != "" {
+			resp.GetFileDescriptorResponse().FileDescriptorProto = [][]byte{encoded}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func TestReflectionMissingDependency(t *testing.T) {
+	client := New(serve(t, func(s *grpc.Server) {
+		rpbv1.RegisterServerReflectionServer(s, omittingReflectionServer{})
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := client.Method(ctx, "/omitting.Service/Method")
+	require.ErrorContains(t, err, "missing.proto")
+}
@@ -0,0 +1,156 @@
+package grpcutil
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type endpointConfig struct {
+	v           verification
+	vSet        bool
+	caPaths     []string
+	token       string
+	tokenSource TokenSource
+	dialOpts    []grpc.DialOption
+}
+
+// EndpointOption configures the connection created by NewEndpointClient.
+type EndpointOption func(*endpointConfig)
+
+// WithEndpointBearerToken adds a standard HTTP Bearer token to all requests,
+// using WithBearerToken or WithInsecureBearerToken to match the endpoint.
+func WithEndpointBearerToken(token string) EndpointOption {
+	return func(c *endpointConfig) {
+		c.token = token
+		c.tokenSource = nil
+	}
+}
+
+// WithEndpointTokenSource adds a Bearer token obtained from the source to all
+// requests, using WithTokenSource or WithInsecureTokenSource to match the
+// endpoint.
+func WithEndpointTokenSource(source TokenSource) EndpointOption {
+	return func(c *endpointConfig) {
+		c.token = ""
+		c.tokenSource = source
+	}
+}
+
+// WithEndpointCerts authenticates a "grpcs" endpoint using a certificate
+// authority chain provided as paths on disk rather than the system-provided
+// chain, as with WithCustomCerts.
+func WithEndpointCerts(certPaths ...string) EndpointOption {
+	return func(c *endpointConfig) { c.caPaths = certPaths }
+}
+
+// WithEndpointVerification sets how a "grpcs" endpoint's certificate is
+// verified. The default is VerifyCA.
+func WithEndpointVerification(v verification) EndpointOption {
+	return func(c *endpointConfig) {
+		c.v = v
+		c.vSet = true
+	}
+}
+
+// WithEndpointDialOptions adds options passed to grpc.NewClient.
+func WithEndpointDialOptions(opts ...grpc.DialOption) EndpointOption {
+	return func(c *endpointConfig) { c.dialOpts = append(c.dialOpts, opts...) }
+}
+
+// NewEndpointClient creates a connection to an endpoint described by a URL,
+// choosing the credentials that match its scheme:
+//
+//   - "grpcs://host[:port]" uses TLS, defaulting to port 443.
+//   - "grpc://host:port" is unencrypted. The port is required, as there is
+//     no conventional port for unencrypted gRPC.
+//   - "unix:///path/to/socket" or "unix:path/to/socket" is an unencrypted
+//     unix socket.
+//
+// WithEndpointCerts and WithEndpointVerification are rejected for endpoints
+// that do not use TLS rather than being ignored.
+func NewEndpointClient(endpoint string, opts ...EndpointOption) (*grpc.ClientConn, error) {
+	target, dialOpts, err := EndpointDialOptions(endpoint, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.NewClient(target, dialOpts...)
+}
+
+// EndpointDialOptions returns the target and dial options that
+// NewEndpointClient would pass to grpc.NewClient for the endpoint.
+func EndpointDialOptions(endpoint string, opts ...EndpointOption) (target string, dialOpts []grpc.DialOption, err error) {
+	config := endpointConfig{v: VerifyCA}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var secure bool
+	switch {
+	case strings.HasPrefix(endpoint, "unix:"):
+		target = endpoint
+	case strings.HasPrefix(endpoint, "grpcs://"), strings.HasPrefix(endpoint, "grpc://"):
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+		secure = u.Scheme == "grpcs"
+		expected := "grpc://host:port"
+		if secure {
+			expected = "grpcs://host[:port]"
+		}
+		if u.Hostname() == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+			return "", nil, fmt.Errorf("invalid endpoint %q: expected %s", endpoint, expected)
+		}
+
+		port := u.Port()
+		switch {
+		case port == "" && secure:
+			port = "443"
+		case port == "":
+			return "", nil, fmt.Errorf("invalid endpoint %q: expected %s", endpoint, expected)
+		}
+		target = net.JoinHostPort(u.Hostname(), port)
+	default:
+		return "", nil, fmt.Errorf("invalid endpoint %q: scheme must be grpcs, grpc or unix", endpoint)
+	}
+
+	if secure {
+		var credsOpt grpc.DialOption
+		if len(config.caPaths) > 0 {
+			credsOpt, err = WithCustomCerts(config.v, config.caPaths...)
+		} else {
+			credsOpt, err = WithSystemCerts(config.v)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		dialOpts = append(dialOpts, credsOpt)
+	} else {
+		if len(config.caPaths) > 0 {
+			return "", nil, errors.New("certificate authorities require a grpcs endpoint")
+		}
+		if config.vSet {
+			return "", nil, errors.New("certificate verification requires a grpcs endpoint")
+		}
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	switch {
+	case config.token != "" && secure:
+		dialOpts = append(dialOpts, WithBearerToken(config.token))
+	case config.token != "":
+		dialOpts = append(dialOpts, WithInsecureBearerToken(config.token))
+	case config.tokenSource != nil && secure:
+		dialOpts = append(dialOpts, WithTokenSource(config.tokenSource))
+	case config.tokenSource != nil:
+		dialOpts = append(dialOpts, WithInsecureTokenSource(config.tokenSource))
+	}
+
+	return target, append(dialOpts, config.dialOpts...), nil
+}
@@ -0,0 +1,127 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+// runAuthenticatedServer runs a Server that requires the bearer token
+// "secret" until the test completes.
+func runAuthenticatedServer(t *testing.T, opts ...ServerBuilderOption) {
+	t.Helper()
+	authFunc := BearerTokenAuthFunc(StaticTokens(map[string]any{"secret": "alice"}))
+	opts = append(opts,
+		WithUnaryMiddleware(grpc_auth.UnaryServerInterceptor(authFunc)),
+		WithShutdownOptions(WithDrainPeriod(0)),
+	)
+	s := NewServer(opts...)
+	testpb.RegisterHelloServiceServer(s, &testServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := make(chan error, 1)
+	go func() {
+		ran <- s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-ran)
+	})
+}
+
+func TestNewEndpointClient(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "server")
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caPath, ca.certPEM)
+
+	tlsOpt, err := WithServerCertificateBytes(certPEM, keyPEM)
+	require.NoError(t, err)
+	tlsLis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	runAuthenticatedServer(t, WithListener(tlsLis), WithServerOptions(tlsOpt))
+	_, tlsPort, err := net.SplitHostPort(tlsLis.Addr().String())
+	require.NoError(t, err)
+
+	plainLis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	runAuthenticatedServer(t, WithListener(plainLis))
+	_, plainPort, err := net.SplitHostPort(plainLis.Addr().String())
+	require.NoError(t, err)
+
+	socketPath := filepath.Join(t.TempDir(), "grpc.sock")
+	runAuthenticatedServer(t, WithListenAddress("unix://"+socketPath))
+
+	for _, tt := range []struct {
+		name     string
+		endpoint string
+		opts     []EndpointOption
+	}{
+		{"tls", "grpcs://localhost:" + tlsPort, []EndpointOption{WithEndpointCerts(caPath)}},
+		{"tls skip verify", "grpcs://localhost:" + tlsPort, []EndpointOption{WithEndpointVerification(SkipVerifyCA)}},
+		{"plaintext", "grpc://localhost:" + plainPort, nil},
+		{"unix", "unix://" + socketPath, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := NewEndpointClient(tt.endpoint, tt.opts...)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+			RequireStatus(t, codes.Unauthenticated, sayHello(testpb.NewHelloServiceClient(conn)))
+
+			conn, err = NewEndpointClient(tt.endpoint, append(tt.opts, WithEndpointBearerToken("secret"))...)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+			require.NoError(t, sayHello(testpb.NewHelloServiceClient(conn)))
+
+			conn, err = NewEndpointClient(tt.endpoint, append(tt.opts, WithEndpointTokenSource(TokenSourceFunc(func(context.Context) (Token, error) {
+				return Token{Value: "secret"}, nil
+			})))...)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+			require.NoError(t, sayHello(testpb.NewHelloServiceClient(conn)))
+		})
+	}
+}
+
+func TestEndpointDialOptions(t *testing.T) {
+	for _, tt := range []struct {
+		endpoint string
+		target   string
+		err      string
+	}{
+		{endpoint: "grpcs://example.com", target: "example.com:443"},
+		{endpoint: "grpcs://example.com:8443/", target: "example.com:8443"},
+		{endpoint: "grpc://[::1]:50051", target: "[::1]:50051"},
+		{endpoint: "grpc://localhost", err: "expected grpc://host:port"},
+		{endpoint: "unix:///run/app.sock", target: "unix:///run/app.sock"},
+		{endpoint: "unix:app.sock", target: "unix:app.sock"},
+		{endpoint: "example.com:443", err: "scheme must be"},
+		{endpoint: "https://example.com", err: "scheme must be"},
+		{endpoint: "grpcs://example.com/path", err: "expected grpcs://host[:port]"},
+		{endpoint: "grpc://", err: "expected grpc://host:port"},
+	} {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			target, _, err := EndpointDialOptions(tt.endpoint)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.target, target)
+		})
+	}
+
+	_, _, err := EndpointDialOptions("grpc://localhost:50051", WithEndpointCerts("ca.pem"))
+	require.ErrorContains(t, err, "require a grpcs endpoint")
+	_, _, err = EndpointDialOptions("unix:///run/app.sock", WithEndpointCerts("ca.pem"))
+	require.ErrorContains(t, err, "require a grpcs endpoint")
+	_, _, err = EndpointDialOptions("unix:///run/app.sock", WithEndpointVerification(SkipVerifyCA))
+	require.ErrorContains(t, err, "requires a grpcs endpoint")
+}
@@ -0,0 +1,263 @@
+package grpcutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	// DefaultProbeInterval is how often a probe is run when no interval has
+	// been provided.
+	DefaultProbeInterval = 10 * time.Second
+
+	// DefaultProbeTimeout is how long a probe may run when no timeout has
+	// been provided.
+	DefaultProbeTimeout = 5 * time.Second
+
+	// DefaultProbeFailureThreshold is how many consecutive failures mark a
+	// probe unhealthy when no threshold has been provided.
+	DefaultProbeFailureThreshold = 3
+)
+
+// Probe checks a dependency, such as a database or downstream service,
+// returning an error if it is unhealthy.
+type Probe func(ctx context.Context) error
+
+// GRPCHealthProbe returns a Probe that checks a service via the gRPC health
+// service on the provided connection. The empty service name checks the
+// server's overall health.
+func GRPCHealthProbe(conn grpc.ClientConnInterface, service string) Probe {
+	client := healthpb.NewHealthClient(conn)
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("service %q is %s", service, resp.GetStatus())
+		}
+		return nil
+	}
+}
+
+type probeConfig struct {
+	interval         time.Duration
+	timeout          time.Duration
+	failureThreshold int
+	services         []string
+}
+
+// ProbeOption configures a probe added to a HealthChecker.
+type ProbeOption func(*probeConfig)
+
+// WithProbeInterval sets how often the probe is run.
+func WithProbeInterval(interval time.Duration) ProbeOption {
+	return func(c *probeConfig) { c.interval = interval }
+}
+
+// WithProbeTimeout sets how long the probe may run before it is cancelled and
+// counted as a failure.
+func WithProbeTimeout(timeout time.Duration) ProbeOption {
+	return func(c *probeConfig) { c.timeout = timeout }
+}
+
+// WithProbeFailureThreshold sets how many consecutive failures mark the
+// probe unhealthy, so that a single failure does not take a service out of
+// rotation.
+func WithProbeFailureThreshold(threshold int) ProbeOption {
+	return func(c *probeConfig) { c.failureThreshold = threshold }
+}
+
+// WithProbeServices sets the services that depend on the probe and are
+// reported NOT_SERVING while it is unhealthy.
+//
+// Every probe affects the overall "" status, including those without
+// services.
+func WithProbeServices(svcDesc ...*grpc.ServiceDesc) ProbeOption {
+	return func(c *probeConfig) {
+		for _, d := range svcDesc {
+			c.services = append(c.services, d.ServiceName)
+		}
+	}
+}
+
+// ProbeStatus is the most recent state of a probe.
+type ProbeStatus struct {
+	// Healthy is false until the probe first succeeds and after it has
+	// failed the failure threshold times in a row.
+	Healthy bool
+
+	// LastError is the error returned by the most recent run, or nil if it
+	// succeeded.
+	LastError error
+
+	// LastChecked is when the most recent run completed.
+	LastChecked time.Time
+
+	// ConsecutiveFailures is how many runs have failed in a row.
+	ConsecutiveFailures int
+}
+
+type healthProbe struct {
+	name  string
+	probe Probe
+	probeConfig
+
+	// status is guarded by HealthChecker.mu.
+	status ProbeStatus
+}
+
+// HealthChecker runs probes in the background and updates the statuses of
+// an AuthlessHealthServer to match.
+//
+// A service is SERVING while every probe it depends on is healthy, and the
+// overall "" status is SERVING while every probe is healthy.
+type HealthChecker struct {
+	server *AuthlessHealthServer
+
+	mu      sync.Mutex
+	probes  []*healthProbe
+	names   map[string]struct{}
+	running context.Context
+}
+
+// NewHealthChecker creates a HealthChecker that reports to the server.
+func NewHealthChecker(server *AuthlessHealthServer) *HealthChecker {
+	return &HealthChecker{server: server, names: make(map[string]struct{})}
+}
+
+// AddProbe registers a probe under a unique name.
+//
+// The services the probe affects are NOT_SERVING until it first succeeds.
+// Probes added after Start begin running immediately.
+func (c *HealthChecker) AddProbe(name string, probe Probe, opts ...ProbeOption) error {
+	p := &healthProbe{
+		name:  name,
+		probe: probe,
+		probeConfig: probeConfig{
+			interval:         DefaultProbeInterval,
+			timeout:          DefaultProbeTimeout,
+			failureThreshold: DefaultProbeFailureThreshold,
+		},
+	}
+	for _, opt := range opts {
+		opt(&p.probeConfig)
+	}
+	if p.interval <= 0 || p.timeout <= 0 || p.failureThreshold <= 0 {
+		return fmt.Errorf("probe %s must have a positive interval, timeout and failure threshold", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.names[name]; ok {
+		return fmt.Errorf("probe %s has already been added", name)
+	}
+	c.names[name] = struct{}{}
+	c.probes = append(c.probes, p)
+	c.updateServer()
+
+	if c.running != nil {
+		go c.run(c.running, p)
+	}
+	return nil
+}
+
+// Start runs the probes in the background until the provided context is
+// cancelled.
+func (c *HealthChecker) Start(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running != nil {
+		return
+	}
+	c.running = ctx
+	for _, p := range c.probes {
+		go c.run(ctx, p)
+	}
+}
+
+// Status returns the status of every probe, keyed by name.
+func (c *HealthChecker) Status() map[string]ProbeStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	statuses := make(map[string]ProbeStatus, len(c.probes))
+	for _, p := range c.probes {
+		statuses[p.name] = p.status
+	}
+	return statuses
+}
+
+func (c *HealthChecker) run(ctx context.Context, p *healthProbe) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		c.check(ctx, p)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *HealthChecker) check(ctx context.Context, p *healthProbe) {
+	probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	err := p.probe(probeCtx)
+	cancel()
+	if ctx.Err() != nil {
+		// Shutting down rather than failing.
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p.status.LastError = err
+	p.status.LastChecked = time.Now()
+	if err == nil {
+		p.status.ConsecutiveFailures = 0
+		p.status.Healthy = true
+	} else {
+		p.status.ConsecutiveFailures++
+		if p.status.ConsecutiveFailures >= p.failureThreshold {
+			p.status.Healthy = false
+		}
+	}
+
+	// Update the server even if the probe's health is unchanged, so that
+	// statuses set elsewhere, such as by Server.RegisterService, do not
+	// outlast the next check.
+	c.updateServer()
+}
+
+// updateServer sets the status of every service that has a probe, along
+// with the overall status. It must be called with c.mu held.
+func (c *HealthChecker) updateServer() {
+	healthy := map[string]bool{"": true}
+	for _, p := range c.probes {
+		for _, service := range append([]string{""}, p.services...) {
+			if _, ok := healthy[service]; !ok {
+				healthy[service] = true
+			}
+			healthy[service] = healthy[service] && p.status.Healthy
+		}
+	}
+
+	for service, ok := range healthy {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ok {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		c.server.SetServingStatus(service, status)
+	}
+}
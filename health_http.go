@@ -0,0 +1,108 @@
+package grpcutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// HealthServiceQueryParam is the query parameter used to select a single
+// service when checking health over HTTP.
+const HealthServiceQueryParam = "service"
+
+// healthHTTPResponse is the JSON body returned by the HTTP health handlers.
+type healthHTTPResponse struct {
+	Status  string   `json:"status"`
+	Service string   `json:"service,omitempty"`
+	Failing []string `json:"failing,omitempty"`
+}
+
+// HTTPHandler returns an http.Handler serving LivenessHandler at "/livez" and
+// ReadinessHandler at "/readyz".
+func (s *AuthlessHealthServer) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/livez", s.LivenessHandler())
+	mux.Handle("/readyz", s.ReadinessHandler())
+	return mux
+}
+
+// LivenessHandler returns an http.Handler suitable for liveness probes.
+//
+// It responds 200 OK whenever the process is able to respond, even while
+// services are NOT_SERVING, because restarting does not help a server that is
+// draining or waiting for a dependency. The JSON body still lists the failing
+// services. A service selected with the "service" query parameter that is not
+// known to the health server responds 404 Not Found.
+func (s *AuthlessHealthServer) LivenessHandler() http.Handler {
+	return s.healthHandler(false)
+}
+
+// ReadinessHandler returns an http.Handler suitable for readiness probes.
+//
+// It responds 200 OK if every service, including the overall "" status, is
+// SERVING and 503 Service Unavailable otherwise, with a JSON body listing the
+// failing services. The "service" query parameter checks a single service
+// instead, responding 404 Not Found if it is not known to the health server.
+func (s *AuthlessHealthServer) ReadinessHandler() http.Handler {
+	return s.healthHandler(true)
+}
+
+func (s *AuthlessHealthServer) healthHandler(readiness bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var resp healthHTTPResponse
+		if r.URL.Query().Has(HealthServiceQueryParam) {
+			resp.Service = r.URL.Query().Get(HealthServiceQueryParam)
+			checked, err := s.Check(r.Context(), &healthpb.HealthCheckRequest{Service: resp.Service})
+			if status.Code(err) == codes.NotFound {
+				resp.Status = healthpb.HealthCheckResponse_SERVICE_UNKNOWN.String()
+				writeHealthResponse(w, http.StatusNotFound, resp)
+				return
+			} else if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if checked.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+				resp.Failing = []string{resp.Service}
+			}
+		} else {
+			listed, err := s.List(r.Context(), &healthpb.HealthListRequest{})
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			for service, checked := range listed.GetStatuses() {
+				if checked.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+					resp.Failing = append(resp.Failing, service)
+				}
+			}
+			slices.Sort(resp.Failing)
+		}
+
+		code := http.StatusOK
+		resp.Status = healthpb.HealthCheckResponse_SERVING.String()
+		if len(resp.Failing) > 0 {
+			resp.Status = healthpb.HealthCheckResponse_NOT_SERVING.String()
+			if readiness {
+				code = http.StatusServiceUnavailable
+			}
+		}
+		writeHealthResponse(w, code, resp)
+	})
+}
+
+func writeHealthResponse(w http.ResponseWriter, code int, resp healthHTTPResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,69 @@
+package grpcutil
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func getHealth(t *testing.T, handler http.Handler, target string) (int, healthHTTPResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var resp healthHTTPResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return rec.Code, resp
+}
+
+func TestHealthHTTPHandler(t *testing.T) {
+	server := NewAuthlessHealthServer()
+	server.SetServicesHealthy(&testpb.HelloService_ServiceDesc)
+	handler := server.HTTPHandler()
+
+	code, resp := getHealth(t, handler, "/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, healthHTTPResponse{Status: "SERVING"}, resp)
+
+	server.SetServingStatus("testpb.HelloService", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	code, resp = getHealth(t, handler, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, healthHTTPResponse{Status: "NOT_SERVING", Failing: []string{"testpb.HelloService"}}, resp)
+
+	code, resp = getHealth(t, handler, "/readyz?service=")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "SERVING", resp.Status)
+
+	code, resp = getHealth(t, handler, "/readyz?service=testpb.HelloService")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "testpb.HelloService", resp.Service)
+
+	code, resp = getHealth(t, handler, "/livez")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, healthHTTPResponse{Status: "NOT_SERVING", Failing: []string{"testpb.HelloService"}}, resp)
+
+	code, resp = getHealth(t, handler, "/livez?service=testpb.Missing")
+	require.Equal(t, http.StatusNotFound, code)
+	require.Equal(t, "SERVICE_UNKNOWN", resp.Status)
+
+	// Shutting down fails readiness but not liveness.
+	server.SetServingStatus("testpb.HelloService", healthpb.HealthCheckResponse_SERVING)
+	server.Shutdown()
+	code, resp = getHealth(t, handler, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.ElementsMatch(t, []string{"", "testpb.HelloService"}, resp.Failing)
+	code, _ = getHealth(t, handler, "/livez")
+	require.Equal(t, http.StatusOK, code)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/readyz", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
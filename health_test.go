@@ -0,0 +1,144 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func servingStatus(t *testing.T, s *AuthlessHealthServer, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.GetStatus()
+}
+
+func requireServingStatus(t *testing.T, s *AuthlessHealthServer, service string, expected healthpb.HealthCheckResponse_ServingStatus) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return servingStatus(t, s, service) == expected
+	}, 5*time.Second, 5*time.Millisecond, "service %q", service)
+}
+
+func TestHealthChecker(t *testing.T) {
+	server := NewAuthlessHealthServer()
+	checker := NewHealthChecker(server)
+
+	var dbErr atomic.Pointer[error]
+	setDBErr := func(err error) { dbErr.Store(&err) }
+	setDBErr(nil)
+
+	require.NoError(t, checker.AddProbe("db", func(context.Context) error {
+		return *dbErr.Load()
+	},
+		WithProbeServices(&testpb.HelloService_ServiceDesc),
+		WithProbeInterval(5*time.Millisecond),
+		WithProbeFailureThreshold(2),
+	))
+	require.NoError(t, checker.AddProbe("cache", func(context.Context) error { return nil },
+		WithProbeInterval(5*time.Millisecond),
+	))
+	require.ErrorContains(t, checker.AddProbe("db", func(context.Context) error { return nil }), "already been added")
+	require.Error(t, checker.AddProbe("invalid", func(context.Context) error { return nil }, WithProbeInterval(0)))
+
+	// Nothing has been checked yet.
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, "testpb.HelloService"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	checker.Start(ctx)
+
+	requireServingStatus(t, server, "", healthpb.HealthCheckResponse_SERVING)
+	requireServingStatus(t, server, "testpb.HelloService", healthpb.HealthCheckResponse_SERVING)
+
+	setDBErr(errors.New("connection refused"))
+	requireServingStatus(t, server, "testpb.HelloService", healthpb.HealthCheckResponse_NOT_SERVING)
+	requireServingStatus(t, server, "", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	status := checker.Status()["db"]
+	require.False(t, status.Healthy)
+	require.ErrorContains(t, status.LastError, "connection refused")
+	require.GreaterOrEqual(t, status.ConsecutiveFailures, 2)
+	require.True(t, checker.Status()["cache"].Healthy)
+
+	setDBErr(nil)
+	requireServingStatus(t, server, "", healthpb.HealthCheckResponse_SERVING)
+	requireServingStatus(t, server, "testpb.HelloService", healthpb.HealthCheckResponse_SERVING)
+
+	// Probes added after starting run immediately.
+	require.NoError(t, checker.AddProbe("late", func(context.Context) error { return errors.New("down") },
+		WithProbeInterval(5*time.Millisecond),
+	))
+	require.Eventually(t, func() bool {
+		return checker.Status()["late"].ConsecutiveFailures > 0
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, server, "testpb.HelloService"))
+}
+
+func TestHealthCheckerWithServer(t *testing.T) {
+	srv := NewServer()
+	checker := NewHealthChecker(srv.Health())
+	require.NoError(t, checker.AddProbe("db", func(context.Context) error { return errors.New("down") },
+		WithProbeServices(&testpb.HelloService_ServiceDesc),
+		WithProbeInterval(5*time.Millisecond),
+	))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	checker.Start(ctx)
+	require.Eventually(t, func() bool {
+		return checker.Status()["db"].ConsecutiveFailures > 0
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Registering the service reports it SERVING until the next check.
+	srv.RegisterService(&testpb.HelloService_ServiceDesc, &testServer{})
+	failures := checker.Status()["db"].ConsecutiveFailures
+	require.Eventually(t, func() bool {
+		return checker.Status()["db"].ConsecutiveFailures > failures
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, srv.Health(), "testpb.HelloService"))
+}
+
+func TestHealthCheckerProbeTimeout(t *testing.T) {
+	server := NewAuthlessHealthServer()
+	checker := NewHealthChecker(server)
+	require.NoError(t, checker.AddProbe("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, WithProbeTimeout(time.Millisecond), WithProbeInterval(5*time.Millisecond)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	checker.Start(ctx)
+
+	require.Eventually(t, func() bool {
+		return errors.Is(checker.Status()["slow"].LastError, context.DeadlineExceeded)
+	}, 5*time.Second, 5*time.Millisecond)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, server, ""))
+}
+
+func TestGRPCHealthProbe(t *testing.T) {
+	downstream := NewAuthlessHealthServer()
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		healthpb.RegisterHealthServer(s, downstream)
+	})
+
+	probe := GRPCHealthProbe(conn, "testpb.HelloService")
+	require.ErrorContains(t, probe(context.Background()), "unknown service")
+
+	downstream.SetServicesHealthy(&testpb.HelloService_ServiceDesc)
+	require.NoError(t, probe(context.Background()))
+
+	downstream.SetServingStatus("testpb.HelloService", healthpb.HealthCheckResponse_NOT_SERVING)
+	require.ErrorContains(t, probe(context.Background()), "NOT_SERVING")
+}
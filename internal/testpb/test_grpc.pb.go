@@ -19,8 +19,10 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	HelloService_HelloUnary_FullMethodName     = "/testpb.HelloService/HelloUnary"
-	HelloService_HelloStreaming_FullMethodName = "/testpb.HelloService/HelloStreaming"
+	HelloService_HelloUnary_FullMethodName           = "/testpb.HelloService/HelloUnary"
+	HelloService_HelloStreaming_FullMethodName       = "/testpb.HelloService/HelloStreaming"
+	HelloService_HelloClientStreaming_FullMethodName = "/testpb.HelloService/HelloClientStreaming"
+	HelloService_HelloBidiStreaming_FullMethodName   = "/testpb.HelloService/HelloBidiStreaming"
 )
 
 // HelloServiceClient is the client API for HelloService service.
@@ -29,6 +31,8 @@ const (
 type HelloServiceClient interface {
 	HelloUnary(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (*HelloResponse, error)
 	HelloStreaming(ctx context.Context, in *HelloRequest, opts ...grpc.CallOption) (HelloService_HelloStreamingClient, error)
+	HelloClientStreaming(ctx context.Context, opts ...grpc.CallOption) (HelloService_HelloClientStreamingClient, error)
+	HelloBidiStreaming(ctx context.Context, opts ...grpc.CallOption) (HelloService_HelloBidiStreamingClient, error)
 }
 
 type helloServiceClient struct {
@@ -80,12 +84,79 @@ func (x *helloServiceHelloStreamingClient) Recv() (*HelloResponse, error) {
 	return m, nil
 }
 
+func (c *helloServiceClient) HelloClientStreaming(ctx context.Context, opts ...grpc.CallOption) (HelloService_HelloClientStreamingClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HelloService_ServiceDesc.Streams[1], HelloService_HelloClientStreaming_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &helloServiceHelloClientStreamingClient{stream}
+	return x, nil
+}
+
+type HelloService_HelloClientStreamingClient interface {
+	Send(*HelloRequest) error
+	CloseAndRecv() (*HelloResponse, error)
+	grpc.ClientStream
+}
+
+type helloServiceHelloClientStreamingClient struct {
+	grpc.ClientStream
+}
+
+func (x *helloServiceHelloClientStreamingClient) Send(m *HelloRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *helloServiceHelloClientStreamingClient) CloseAndRecv() (*HelloResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(HelloResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *helloServiceClient) HelloBidiStreaming(ctx context.Context, opts ...grpc.CallOption) (HelloService_HelloBidiStreamingClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HelloService_ServiceDesc.Streams[2], HelloService_HelloBidiStreaming_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &helloServiceHelloBidiStreamingClient{stream}
+	return x, nil
+}
+
+type HelloService_HelloBidiStreamingClient interface {
+	Send(*HelloRequest) error
+	Recv() (*HelloResponse, error)
+	grpc.ClientStream
+}
+
+type helloServiceHelloBidiStreamingClient struct {
+	grpc.ClientStream
+}
+
+func (x *helloServiceHelloBidiStreamingClient) Send(m *HelloRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *helloServiceHelloBidiStreamingClient) Recv() (*HelloResponse, error) {
+	m := new(HelloResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // HelloServiceServer is the server API for HelloService service.
 // All implementations must embed UnimplementedHelloServiceServer
 // for forward compatibility
 type HelloServiceServer interface {
 	HelloUnary(context.Context, *HelloRequest) (*HelloResponse, error)
 	HelloStreaming(*HelloRequest, HelloService_HelloStreamingServer) error
+	HelloClientStreaming(HelloService_HelloClientStreamingServer) error
+	HelloBidiStreaming(HelloService_HelloBidiStreamingServer) error
 	mustEmbedUnimplementedHelloServiceServer()
 }
 
@@ -99,6 +170,12 @@ func (UnimplementedHelloServiceServer) HelloUnary(context.Context, *HelloRequest
 func (UnimplementedHelloServiceServer) HelloStreaming(*HelloRequest, HelloService_HelloStreamingServer) error {
 	return status.Errorf(codes.Unimplemented, "method HelloStreaming not implemented")
 }
+func (UnimplementedHelloServiceServer) HelloClientStreaming(HelloService_HelloClientStreamingServer) error {
+	return status.Errorf(codes.Unimplemented, "method HelloClientStreaming not implemented")
+}
+func (UnimplementedHelloServiceServer) HelloBidiStreaming(HelloService_HelloBidiStreamingServer) error {
+	return status.Errorf(codes.Unimplemented, "method HelloBidiStreaming not implemented")
+}
 func (UnimplementedHelloServiceServer) mustEmbedUnimplementedHelloServiceServer() {}
 
 // UnsafeHelloServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -151,6 +228,58 @@ func (x *helloServiceHelloStreamingServer) Send(m *HelloResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _HelloService_HelloClientStreaming_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HelloServiceServer).HelloClientStreaming(&helloServiceHelloClientStreamingServer{stream})
+}
+
+type HelloService_HelloClientStreamingServer interface {
+	SendAndClose(*HelloResponse) error
+	Recv() (*HelloRequest, error)
+	grpc.ServerStream
+}
+
+type helloServiceHelloClientStreamingServer struct {
+	grpc.ServerStream
+}
+
+func (x *helloServiceHelloClientStreamingServer) SendAndClose(m *HelloResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *helloServiceHelloClientStreamingServer) Recv() (*HelloRequest, error) {
+	m := new(HelloRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _HelloService_HelloBidiStreaming_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(HelloServiceServer).HelloBidiStreaming(&helloServiceHelloBidiStreamingServer{stream})
+}
+
+type HelloService_HelloBidiStreamingServer interface {
+	Send(*HelloResponse) error
+	Recv() (*HelloRequest, error)
+	grpc.ServerStream
+}
+
+type helloServiceHelloBidiStreamingServer struct {
+	grpc.ServerStream
+}
+
+func (x *helloServiceHelloBidiStreamingServer) Send(m *HelloResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *helloServiceHelloBidiStreamingServer) Recv() (*HelloRequest, error) {
+	m := new(HelloRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // HelloService_ServiceDesc is the grpc.ServiceDesc for HelloService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -169,6 +298,17 @@ var HelloService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _HelloService_HelloStreaming_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "HelloClientStreaming",
+			Handler:       _HelloService_HelloClientStreaming_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "HelloBidiStreaming",
+			Handler:       _HelloService_HelloBidiStreaming_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "test.proto",
 }
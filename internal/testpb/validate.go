@@ -0,0 +1,12 @@
+package testpb
+
+import "errors"
+
+// Validate implements the interface used by validation middleware, rejecting
+// requests whose message is "invalid".
+func (x *HelloRequest) Validate() error {
+	if x.GetMessage() == "invalid" {
+		return errors.New("invalid HelloRequest.Message: value must not be \"invalid\"")
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: validated.proto
+
+package testpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ValidatedService_ValidatedUnary_FullMethodName         = "/testpb.ValidatedService/ValidatedUnary"
+	ValidatedService_ValidatedBidiStreaming_FullMethodName = "/testpb.ValidatedService/ValidatedBidiStreaming"
+)
+
+// ValidatedServiceClient is the client API for ValidatedService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ValidatedServiceClient interface {
+	ValidatedUnary(ctx context.Context, in *ValidatedRequest, opts ...grpc.CallOption) (*HelloResponse, error)
+	ValidatedBidiStreaming(ctx context.Context, opts ...grpc.CallOption) (ValidatedService_ValidatedBidiStreamingClient, error)
+}
+
+type validatedServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewValidatedServiceClient(cc grpc.ClientConnInterface) ValidatedServiceClient {
+	return &validatedServiceClient{cc}
+}
+
+func (c *validatedServiceClient) ValidatedUnary(ctx context.Context, in *ValidatedRequest, opts ...grpc.CallOption) (*HelloResponse, error) {
+	out := new(HelloResponse)
+	err := c.cc.Invoke(ctx, ValidatedService_ValidatedUnary_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *validatedServiceClient) ValidatedBidiStreaming(ctx context.Context, opts ...grpc.CallOption) (ValidatedService_ValidatedBidiStreamingClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ValidatedService_ServiceDesc.Streams[0], ValidatedService_ValidatedBidiStreaming_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &validatedServiceValidatedBidiStreamingClient{stream}
+	return x, nil
+}
+
+type ValidatedService_ValidatedBidiStreamingClient interface {
+	Send(*ValidatedRequest) error
+	Recv() (*HelloResponse, error)
+	grpc.ClientStream
+}
+
+type validatedServiceValidatedBidiStreamingClient struct {
+	grpc.ClientStream
+}
+
+func (x *validatedServiceValidatedBidiStreamingClient) Send(m *ValidatedRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *validatedServiceValidatedBidiStreamingClient) Recv() (*HelloResponse, error) {
+	m := new(HelloResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ValidatedServiceServer is the server API for ValidatedService service.
+// All implementations must embed UnimplementedValidatedServiceServer
+// for forward compatibility
+type ValidatedServiceServer interface {
+	ValidatedUnary(context.Context, *ValidatedRequest) (*HelloResponse, error)
+	ValidatedBidiStreaming(ValidatedService_ValidatedBidiStreamingServer) error
+	mustEmbedUnimplementedValidatedServiceServer()
+}
+
+// UnimplementedValidatedServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedValidatedServiceServer struct {
+}
+
+func (UnimplementedValidatedServiceServer) ValidatedUnary(context.Context, *ValidatedRequest) (*HelloResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidatedUnary not implemented")
+}
+func (UnimplementedValidatedServiceServer) ValidatedBidiStreaming(ValidatedService_ValidatedBidiStreamingServer) error {
+	return status.Errorf(codes.Unimplemented, "method ValidatedBidiStreaming not implemented")
+}
+func (UnimplementedValidatedServiceServer) mustEmbedUnimplementedValidatedServiceServer() {}
+
+// UnsafeValidatedServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ValidatedServiceServer will
+// result in compilation errors.
+type UnsafeValidatedServiceServer interface {
+	mustEmbedUnimplementedValidatedServiceServer()
+}
+
+func RegisterValidatedServiceServer(s grpc.ServiceRegistrar, srv ValidatedServiceServer) {
+	s.RegisterService(&ValidatedService_ServiceDesc, srv)
+}
+
+func _ValidatedService_ValidatedUnary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidatedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ValidatedServiceServer).ValidatedUnary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ValidatedService_ValidatedUnary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ValidatedServiceServer).ValidatedUnary(ctx, req.(*ValidatedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ValidatedService_ValidatedBidiStreaming_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ValidatedServiceServer).ValidatedBidiStreaming(&validatedServiceValidatedBidiStreamingServer{stream})
+}
+
+type ValidatedService_ValidatedBidiStreamingServer interface {
+	Send(*HelloResponse) error
+	Recv() (*ValidatedRequest, error)
+	grpc.ServerStream
+}
+
+type validatedServiceValidatedBidiStreamingServer struct {
+	grpc.ServerStream
+}
+
+func (x *validatedServiceValidatedBidiStreamingServer) Send(m *HelloResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *validatedServiceValidatedBidiStreamingServer) Recv() (*ValidatedRequest, error) {
+	m := new(ValidatedRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ValidatedService_ServiceDesc is the grpc.ServiceDesc for ValidatedService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ValidatedService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "testpb.ValidatedService",
+	HandlerType: (*ValidatedServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidatedUnary",
+			Handler:    _ValidatedService_ValidatedUnary_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ValidatedBidiStreaming",
+			Handler:       _ValidatedService_ValidatedBidiStreaming_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "validated.proto",
+}
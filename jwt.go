@@ -0,0 +1,363 @@
+package grpcutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// DefaultJWKSCacheDuration is how long a JWKS document is cached when no
+// duration has been provided.
+const DefaultJWKSCacheDuration = 5 * time.Minute
+
+// DefaultJWTAlgorithms are the signature algorithms accepted by a JWTValidator
+// when none have been provided via WithJWTAlgorithms.
+var DefaultJWTAlgorithms = []string{
+	string(jose.RS256), string(jose.RS384), string(jose.RS512),
+	string(jose.PS256), string(jose.PS384), string(jose.PS512),
+	string(jose.ES256), string(jose.ES384), string(jose.ES512),
+	string(jose.EdDSA),
+}
+
+// JWTClaims are the claims of a validated JWT.
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	Expiry    time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+
+	// Raw contains every claim of the token, including those above.
+	Raw map[string]any
+}
+
+// JWTClaimsFromContext returns the claims of the JWT that authenticated the
+// request, if it was authenticated by a JWTValidator.
+func JWTClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	return PrincipalFromContext[*JWTClaims](ctx)
+}
+
+// HasScope returns whether the "scope" claim, a space-separated list, or the
+// "scp" claim, a list, contains the scope.
+func (c *JWTClaims) HasScope(scope string) bool {
+	switch scopes := c.Raw["scope"].(type) {
+	case string:
+		if slices.Contains(strings.Fields(scopes), scope) {
+			return true
+		}
+	case []any:
+		if slices.Contains(scopes, any(scope)) {
+			return true
+		}
+	}
+	if scopes, ok := c.Raw["scp"].([]any); ok {
+		return slices.Contains(scopes, any(scope))
+	}
+	return false
+}
+
+// JWTKeySource provides the keys used to verify JWT signatures.
+type JWTKeySource interface {
+	// Keys returns the current set of verification keys. If refresh is true,
+	// cached keys should be reloaded because a token referenced an unknown
+	// key.
+	Keys(ctx context.Context, refresh bool) (*jose.JSONWebKeySet, error)
+}
+
+type staticJWTKeys struct {
+	set jose.JSONWebKeySet
+}
+
+// StaticJWTKeys returns a JWTKeySource for a fixed set of public keys, such
+// as *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey.
+func StaticJWTKeys(keys ...any) JWTKeySource {
+	var set staticJWTKeys
+	for _, key := range keys {
+		set.set.Keys = append(set.set.Keys, jose.JSONWebKey{Key: key})
+	}
+	return &set
+}
+
+func (s *staticJWTKeys) Keys(context.Context, bool) (*jose.JSONWebKeySet, error) {
+	return &s.set, nil
+}
+
+// JWKSFile returns a JWTKeySource that loads a JWKS document from a path on
+// disk, caching it for cacheDuration.
+func JWKSFile(path string, cacheDuration time.Duration) JWTKeySource {
+	return newCachedJWKS(cacheDuration, func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	})
+}
+
+// JWKSURL returns a JWTKeySource that fetches a JWKS document from a URL,
+// caching it for cacheDuration.
+//
+// If client is nil, an http.Client with a timeout of JWKSFetchTimeout is
+// used.
+func JWKSURL(url string, cacheDuration time.Duration, client *http.Client) JWTKeySource {
+	if client == nil {
+		client = &http.Client{Timeout: JWKSFetchTimeout}
+	}
+	return newCachedJWKS(cacheDuration, func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status fetching JWKS: %s", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	})
+}
+
+// JWKSFetchTimeout bounds how long loading a JWKS document may take.
+const JWKSFetchTimeout = 30 * time.Second
+
+// minJWKSRefreshInterval limits how often an unknown key ID or a failed load
+// can cause the JWKS document to be reloaded.
+const minJWKSRefreshInterval = 10 * time.Second
+
+type cachedJWKS struct {
+	cacheDuration time.Duration
+	fetch         func(context.Context) ([]byte, error)
+	now           func() time.Time
+
+	mu          sync.Mutex
+	keys        *jose.JSONWebKeySet
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	err         error
+
+	// loading is closed once the load in progress, if any, has completed.
+	loading chan struct{}
+}
+
+func newCachedJWKS(cacheDuration time.Duration, fetch func(context.Context) ([]byte, error)) *cachedJWKS {
+	if cacheDuration <= 0 {
+		cacheDuration = DefaultJWKSCacheDuration
+	}
+	return &cachedJWKS{cacheDuration: cacheDuration, fetch: fetch, now: time.Now}
+}
+
+// Keys returns the cached document, loading it if it has expired. Concurrent
+// callers share a single load, which happens without holding the lock, and
+// each stops waiting for it when its own context is done.
+func (c *cachedJWKS) Keys(ctx context.Context, refresh bool) (*jose.JSONWebKeySet, error) {
+	c.mu.Lock()
+	now := c.now()
+	sinceAttempt := now.Sub(c.attemptedAt)
+	switch {
+	case c.keys != nil && now.Sub(c.fetchedAt) < c.cacheDuration && (!refresh || sinceAttempt < minJWKSRefreshInterval):
+		defer c.mu.Unlock()
+		return c.keys, nil
+	case c.err != nil && sinceAttempt < minJWKSRefreshInterval:
+		// Back off after a failed load rather than retrying on every request.
+		defer c.mu.Unlock()
+		return c.result()
+	}
+
+	loading := c.loading
+	if loading == nil {
+		loading = make(chan struct{})
+		c.loading = loading
+		c.attemptedAt = now
+		go c.load(context.WithoutCancel(ctx), loading)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-loading:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result()
+}
+
+func (c *cachedJWKS) load(ctx context.Context, done chan struct{}) {
+	ctx, cancel := context.WithTimeout(ctx, JWKSFetchTimeout)
+	defer cancel()
+
+	var keys jose.JSONWebKeySet
+	contents, err := c.fetch(ctx)
+	if err == nil {
+		err = json.Unmarshal(contents, &keys)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+	if err == nil {
+		c.keys = &keys
+		c.fetchedAt = c.now()
+	}
+	c.loading = nil
+	close(done)
+}
+
+// result returns the most recently loaded document, which is served even
+// when stale rather than failing every request. It must be called with the
+// lock held.
+func (c *cachedJWKS) result() (*jose.JSONWebKeySet, error) {
+	if c.keys != nil {
+		return c.keys, nil
+	}
+	return nil, fmt.Errorf("failed to load JWKS: %w", c.err)
+}
+
+// JWTValidatorOption configures a JWTValidator.
+type JWTValidatorOption func(*JWTValidator)
+
+// WithJWTIssuer requires the "iss" claim to match the issuer.
+func WithJWTIssuer(issuer string) JWTValidatorOption {
+	return func(v *JWTValidator) { v.issuer = issuer }
+}
+
+// WithJWTAudience requires the "aud" claim to contain at least one of the
+// audiences.
+func WithJWTAudience(audiences ...string) JWTValidatorOption {
+	return func(v *JWTValidator) { v.audiences = audiences }
+}
+
+// WithJWTClockSkew sets the leeway allowed when checking the "exp", "nbf" and
+// "iat" claims.
+func WithJWTClockSkew(skew time.Duration) JWTValidatorOption {
+	return func(v *JWTValidator) { v.clockSkew = skew }
+}
+
+// WithJWTAlgorithms sets the signature algorithms that are accepted.
+func WithJWTAlgorithms(algorithms ...string) JWTValidatorOption {
+	return func(v *JWTValidator) {
+		v.algorithms = v.algorithms[:0]
+		for _, alg := range algorithms {
+			v.algorithms = append(v.algorithms, jose.SignatureAlgorithm(alg))
+		}
+	}
+}
+
+// JWTValidator is a TokenValidator that accepts signed JWTs.
+//
+// Use it with BearerTokenAuthFunc to authenticate requests, after which the
+// token's claims are available via JWTClaimsFromContext.
+type JWTValidator struct {
+	keys       JWTKeySource
+	issuer     string
+	audiences  []string
+	clockSkew  time.Duration
+	algorithms []jose.SignatureAlgorithm
+	now        func() time.Time
+}
+
+var _ TokenValidator = (*JWTValidator)(nil)
+
+// NewJWTValidator creates a JWTValidator that verifies signatures using the
+// provided keys.
+//
+// Tokens are required to have an "exp" claim.
+func NewJWTValidator(keys JWTKeySource, opts ...JWTValidatorOption) *JWTValidator {
+	v := &JWTValidator{
+		keys:      keys,
+		clockSkew: jwt.DefaultLeeway,
+		now:       time.Now,
+	}
+	WithJWTAlgorithms(DefaultJWTAlgorithms...)(v)
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// ValidateToken implements TokenValidator, returning the token's *JWTClaims
+// as the principal.
+func (v *JWTValidator) ValidateToken(ctx context.Context, token string) (any, error) {
+	parsed, err := jwt.ParseSigned(token, v.algorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	var claims jwt.Claims
+	var raw map[string]any
+	if err := v.verify(ctx, parsed, &claims, &raw); err != nil {
+		return nil, err
+	}
+
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if err := claims.ValidateWithLeeway(jwt.Expected{
+		Issuer:      v.issuer,
+		AnyAudience: v.audiences,
+		Time:        v.now(),
+	}, v.clockSkew); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return &JWTClaims{
+		Issuer:    claims.Issuer,
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		Expiry:    claims.Expiry.Time(),
+		NotBefore: numericDateTime(claims.NotBefore),
+		IssuedAt:  numericDateTime(claims.IssuedAt),
+		ID:        claims.ID,
+		Raw:       raw,
+	}, nil
+}
+
+func (v *JWTValidator) verify(ctx context.Context, parsed *jwt.JSONWebToken, dest ...any) error {
+	var kid string
+	if len(parsed.Headers) > 0 {
+		kid = parsed.Headers[0].KeyID
+	}
+
+	for _, refresh := range []bool{false, true} {
+		keys, err := v.keys.Keys(ctx, refresh)
+		if err != nil {
+			return err
+		}
+
+		candidates := keys.Keys
+		if kid != "" {
+			candidates = keys.Key(kid)
+		}
+		for _, key := range candidates {
+			if err := parsed.Claims(key, dest...); err == nil {
+				return nil
+			}
+		}
+
+		if kid == "" || len(candidates) > 0 {
+			break
+		}
+	}
+
+	return fmt.Errorf("%w: signature could not be verified", ErrInvalidToken)
+}
+
+func numericDateTime(d *jwt.NumericDate) time.Time {
+	if d == nil {
+		return time.Time{}
+	}
+	return d.Time()
+}
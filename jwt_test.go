@@ -0,0 +1,275 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+type testJWTKey struct {
+	kid string
+	key *ecdsa.PrivateKey
+}
+
+func newTestJWTKey(t *testing.T, kid string) testJWTKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testJWTKey{kid: kid, key: key}
+}
+
+func (k testJWTKey) public() jose.JSONWebKey {
+	return jose.JSONWebKey{Key: &k.key.PublicKey, KeyID: k.kid, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+func (k testJWTKey) sign(t *testing.T, claims any) string {
+	t.Helper()
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if k.kid != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), k.kid)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: k.key}, opts)
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func jwksJSON(t *testing.T, keys ...testJWTKey) []byte {
+	t.Helper()
+	var set jose.JSONWebKeySet
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.public())
+	}
+	contents, err := json.Marshal(set)
+	require.NoError(t, err)
+	return contents
+}
+
+func validClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "alice",
+		"aud":   []string{"spicedb"},
+		"exp":   now.Add(time.Hour).Unix(),
+		"nbf":   now.Add(-time.Minute).Unix(),
+		"scope": "read write",
+	}
+}
+
+func TestJWTValidatorWithJWKSURL(t *testing.T) {
+	first := newTestJWTKey(t, "first")
+	second := newTestJWTKey(t, "second")
+
+	var mu sync.Mutex
+	served := jwksJSON(t, first)
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		_, _ = w.Write(served)
+	}))
+	t.Cleanup(srv.Close)
+
+	keys := JWKSURL(srv.URL, time.Hour, srv.Client()).(*cachedJWKS)
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	authFunc := BearerTokenAuthFunc(NewJWTValidator(keys,
+		WithJWTIssuer("https://issuer.example.com"),
+		WithJWTAudience("spicedb"),
+	))
+
+	ctx, err := authFunc(withAuthorization("Bearer " + first.sign(t, validClaims(now))))
+	require.NoError(t, err)
+	claims, ok := JWTClaimsFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "alice", claims.Subject)
+	require.Equal(t, []string{"spicedb"}, claims.Audience)
+	require.Equal(t, "read write", claims.Raw["scope"])
+
+	_, err = authFunc(withAuthorization("Bearer " + first.sign(t, validClaims(now))))
+	require.NoError(t, err)
+	require.Equal(t, 1, fetches, "JWKS should be cached")
+
+	// A token signed by a key that is not yet known forces a reload once the
+	// minimum refresh interval has passed.
+	mu.Lock()
+	served = jwksJSON(t, first, second)
+	mu.Unlock()
+	_, err = authFunc(withAuthorization("Bearer " + second.sign(t, validClaims(now))))
+	RequireStatus(t, codes.Unauthenticated, err)
+
+	now = now.Add(minJWKSRefreshInterval)
+	_, err = authFunc(withAuthorization("Bearer " + second.sign(t, validClaims(now))))
+	require.NoError(t, err)
+	require.Equal(t, 2, fetches)
+}
+
+func TestJWTValidatorClaims(t *testing.T) {
+	key := newTestJWTKey(t, "")
+	now := time.Now()
+	validator := NewJWTValidator(StaticJWTKeys(&key.key.PublicKey),
+		WithJWTIssuer("https://issuer.example.com"),
+		WithJWTAudience("spicedb"),
+		WithJWTClockSkew(time.Minute),
+	)
+	validator.now = func() time.Time { return now }
+
+	tcs := []struct {
+		name    string
+		modify  func(claims map[string]any)
+		wantErr bool
+	}{
+		{"valid", func(map[string]any) {}, false},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, true},
+		{"wrong audience", func(c map[string]any) { c["aud"] = []string{"other"} }, true},
+		{"missing expiry", func(c map[string]any) { delete(c, "exp") }, true},
+		{"expired", func(c map[string]any) { c["exp"] = now.Add(-2 * time.Minute).Unix() }, true},
+		{"expired within skew", func(c map[string]any) { c["exp"] = now.Add(-30 * time.Second).Unix() }, false},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = now.Add(2 * time.Minute).Unix() }, true},
+		{"not yet valid within skew", func(c map[string]any) { c["nbf"] = now.Add(30 * time.Second).Unix() }, false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := validClaims(now)
+			tc.modify(claims)
+			_, err := validator.ValidateToken(context.Background(), key.sign(t, claims))
+			if tc.wantErr {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	other := newTestJWTKey(t, "")
+	_, err := validator.ValidateToken(context.Background(), other.sign(t, validClaims(now)))
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = validator.ValidateToken(context.Background(), "not.a.jwt")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestJWKSFile(t *testing.T) {
+	key := newTestJWTKey(t, "file")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeFile(t, path, jwksJSON(t, key))
+
+	validator := NewJWTValidator(JWKSFile(path, 0))
+	_, err := validator.ValidateToken(context.Background(), key.sign(t, validClaims(time.Now())))
+	require.NoError(t, err)
+
+	_, err = NewJWTValidator(JWKSFile(filepath.Join(t.TempDir(), "missing.json"), 0)).
+		ValidateToken(context.Background(), key.sign(t, validClaims(time.Now())))
+	require.ErrorContains(t, err, "failed to load JWKS")
+}
+
+func TestJWKSURLFailures(t *testing.T) {
+	key := newTestJWTKey(t, "key")
+
+	var mu sync.Mutex
+	fail := false
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(jwksJSON(t, key))
+	}))
+	t.Cleanup(srv.Close)
+
+	keys := JWKSURL(srv.URL, time.Minute, srv.Client()).(*cachedJWKS)
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	_, err := keys.Keys(context.Background(), false)
+	require.NoError(t, err)
+
+	// Once expired, a failed load serves the stale keys and is not retried
+	// until the minimum refresh interval has passed.
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	now = now.Add(time.Minute)
+	for range 3 {
+		set, err := keys.Keys(context.Background(), false)
+		require.NoError(t, err)
+		require.Len(t, set.Keys, 1)
+	}
+	require.Equal(t, 2, fetches)
+
+	now = now.Add(minJWKSRefreshInterval)
+	_, err = keys.Keys(context.Background(), false)
+	require.NoError(t, err)
+	require.Equal(t, 3, fetches)
+
+	// Without stale keys, the failure is returned until the next attempt.
+	failing := JWKSURL(srv.URL, time.Minute, srv.Client())
+	_, err = failing.Keys(context.Background(), false)
+	require.ErrorContains(t, err, "failed to load JWKS")
+	_, err = failing.Keys(context.Background(), false)
+	require.ErrorContains(t, err, "500")
+	require.Equal(t, 4, fetches)
+}
+
+func TestJWKSURLHanging(t *testing.T) {
+	release := make(chan struct{})
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	keys := JWKSURL(srv.URL, time.Minute, srv.Client())
+
+	// Callers waiting on a hanging load give up when their own context is
+	// done, and share the load rather than starting another.
+	var wg sync.WaitGroup
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := keys.Keys(ctx, false)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		}()
+	}
+	wg.Wait()
+	require.Eventually(t, func() bool {
+		return fetches.Load() == 1
+	}, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestJWTClaimsHasScope(t *testing.T) {
+	claims := &JWTClaims{Raw: map[string]any{"scope": "read write"}}
+	require.True(t, claims.HasScope("write"))
+	require.False(t, claims.HasScope("admin"))
+
+	claims = &JWTClaims{Raw: map[string]any{"scp": []any{"admin"}}}
+	require.True(t, claims.HasScope("admin"))
+	require.False(t, claims.HasScope("read"))
+}
@@ -52,10 +52,42 @@ func (s *AuthlessHealthServer) SetServicesHealthy(svcDesc ...*grpc.ServiceDesc)
 // applicable.
 var DefaultUnaryMiddleware = []grpc.UnaryServerInterceptor{grpcvalidate.UnaryServerInterceptor()}
 
+// DefaultStreamMiddleware is a recommended set of middleware that should each gracefully no-op if the middleware is not
+// applicable.
+//
+// Every message received on a stream is validated, which covers each request of client and bidirectional streams and
+// the initial request of server streams.
+var DefaultStreamMiddleware = []grpc.StreamServerInterceptor{grpcvalidate.StreamServerInterceptor()}
+
+// Placement determines where the interceptors passed to WrapMethodsWithPlacement
+// and WrapStreamsWithPlacement run relative to global interceptors.
+type Placement int
+
+const (
+	// AfterGlobalInterceptors runs the wrapped interceptors after, and
+	// therefore nested within, the global interceptors.
+	AfterGlobalInterceptors Placement = iota
+
+	// BeforeGlobalInterceptors runs the wrapped interceptors before, and
+	// therefore wrapped around, the global interceptors.
+	BeforeGlobalInterceptors
+)
+
 // WrapMethods wraps all non-streaming endpoints with the given list of interceptors.
 // It returns a copy of the ServiceDesc with the new wrapped methods.
+//
+// The interceptors run after any interceptors configured on the server.
 func WrapMethods(svcDesc grpc.ServiceDesc, interceptors ...grpc.UnaryServerInterceptor) (wrapped *grpc.ServiceDesc) {
+	return WrapMethodsWithPlacement(svcDesc, AfterGlobalInterceptors, interceptors...)
+}
+
+// WrapMethodsWithPlacement wraps all non-streaming endpoints with the given
+// list of interceptors, placing them relative to the interceptors configured
+// on the server.
+// It returns a copy of the ServiceDesc with the new wrapped methods.
+func WrapMethodsWithPlacement(svcDesc grpc.ServiceDesc, placement Placement, interceptors ...grpc.UnaryServerInterceptor) (wrapped *grpc.ServiceDesc) {
 	chain := grpcmw.ChainUnaryServer(interceptors...)
+	methods := make([]grpc.MethodDesc, len(svcDesc.Methods))
 	for i, m := range svcDesc.Methods {
 		handler := m.Handler
 		wrapped := grpc.MethodDesc{
@@ -64,35 +96,65 @@ func WrapMethods(svcDesc grpc.ServiceDesc, interceptors ...grpc.UnaryServerInter
 				if interceptor == nil {
 					interceptor = NoopUnaryInterceptor
 				}
+				if placement == BeforeGlobalInterceptors {
+					return handler(srv, ctx, dec, grpcmw.ChainUnaryServer(chain, interceptor))
+				}
 				return handler(srv, ctx, dec, grpcmw.ChainUnaryServer(interceptor, chain))
 			},
 		}
-		svcDesc.Methods[i] = wrapped
+		methods[i] = wrapped
 	}
+	svcDesc.Methods = methods
 	return &svcDesc
 }
 
 // WrapStreams wraps all streaming endpoints with the given list of interceptors.
 // It returns a copy of the ServiceDesc with the new wrapped methods.
+//
+// The interceptors run after any interceptors configured on the server.
 func WrapStreams(svcDesc grpc.ServiceDesc, interceptors ...grpc.StreamServerInterceptor) (wrapped *grpc.ServiceDesc) {
+	return WrapStreamsWithPlacement(svcDesc, AfterGlobalInterceptors, nil, interceptors...)
+}
+
+// WrapStreamsWithPlacement wraps all streaming endpoints with the given list
+// of interceptors, placing them relative to the global interceptor.
+// It returns a copy of the ServiceDesc with the new wrapped methods.
+//
+// Unlike unary interceptors, gRPC always runs the stream interceptors
+// configured on the server before the service's handler, so they cannot be
+// reordered. To run the interceptors before a global stream interceptor, pass
+// it as global instead of configuring it on the server, and it will be
+// invoked for these streams in the requested order. global may be nil.
+func WrapStreamsWithPlacement(svcDesc grpc.ServiceDesc, placement Placement, global grpc.StreamServerInterceptor, interceptors ...grpc.StreamServerInterceptor) (wrapped *grpc.ServiceDesc) {
 	chain := grpcmw.ChainStreamServer(interceptors...)
+	if global != nil {
+		if placement == BeforeGlobalInterceptors {
+			chain = grpcmw.ChainStreamServer(chain, global)
+		} else {
+			chain = grpcmw.ChainStreamServer(global, chain)
+		}
+	}
+
+	streams := make([]grpc.StreamDesc, len(svcDesc.Streams))
 	for i, s := range svcDesc.Streams {
 		handler := s.Handler
-		info := &grpc.StreamServerInfo{
-			FullMethod:     fmt.Sprintf("/%s/%s", svcDesc.ServiceName, s.StreamName),
-			IsClientStream: s.ClientStreams,
-			IsServerStream: s.ServerStreams,
-		}
+		fullMethod := fmt.Sprintf("/%s/%s", svcDesc.ServiceName, s.StreamName)
 		wrapped := grpc.StreamDesc{
 			StreamName:    s.StreamName,
 			ClientStreams: s.ClientStreams,
 			ServerStreams: s.ServerStreams,
 			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				info := &grpc.StreamServerInfo{
+					FullMethod:     fullMethod,
+					IsClientStream: s.ClientStreams,
+					IsServerStream: s.ServerStreams,
+				}
 				return chain(srv, stream, info, handler)
 			},
 		}
-		svcDesc.Streams[i] = wrapped
+		streams[i] = wrapped
 	}
+	svcDesc.Streams = streams
 	return &svcDesc
 }
 
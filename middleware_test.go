@@ -3,14 +3,19 @@ package grpcutil
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/authzed/grpcutil/internal/testpb"
 
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
 )
@@ -98,7 +103,7 @@ func TestWrapMethodsAndServerInterceptor(t *testing.T) {
 	require.NoError(t, err)
 
 	// middleware happens before server middleware
-	require.Equal(t, "yep,sup,friend", resp.Message, "request not intercepted, got %s", resp.Message)
+	require.Equal(t, "hi,sup,friend", resp.Message, "request not intercepted, got %s", resp.Message)
 }
 
 func TestWrapStreams(t *testing.T) {
@@ -172,6 +177,190 @@ func TestWrapStreamsAndServerInterceptor(t *testing.T) {
 	require.Equal(t, 1, serverCounter, "server stream not intercepted")
 }
 
+// handlerPointers identifies the handlers of a ServiceDesc, so that tests can
+// check that wrapping it leaves the original unchanged.
+func handlerPointers(desc grpc.ServiceDesc) []uintptr {
+	var pointers []uintptr
+	for _, m := range desc.Methods {
+		pointers = append(pointers, reflect.ValueOf(m.Handler).Pointer())
+	}
+	for _, s := range desc.Streams {
+		pointers = append(pointers, reflect.ValueOf(s.Handler).Pointer())
+	}
+	return pointers
+}
+
+func serveDesc(t *testing.T, desc *grpc.ServiceDesc, opts ...grpc.ServerOption) testpb.HelloServiceClient {
+	t.Helper()
+	_, dial := serve(t, func(s *grpc.Server) {
+		s.RegisterService(desc, &testServer{})
+	}, opts...)
+	return testpb.NewHelloServiceClient(dial(grpc.WithTransportCredentials(insecure.NewCredentials())))
+}
+
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *callRecorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, name)
+}
+
+func (r *callRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func (r *callRecorder) unary(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		r.record(name)
+		return handler(ctx, req)
+	}
+}
+
+func (r *callRecorder) stream(name string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		r.record(fmt.Sprintf("%s:%s:%t:%t", name, info.FullMethod, info.IsClientStream, info.IsServerStream))
+		return handler(srv, stream)
+	}
+}
+
+func TestWrapMethodsWithPlacement(t *testing.T) {
+	for _, tc := range []struct {
+		placement Placement
+		expected  []string
+	}{
+		{AfterGlobalInterceptors, []string{"global", "a", "b"}},
+		{BeforeGlobalInterceptors, []string{"a", "b", "global"}},
+	} {
+		var rec callRecorder
+		original := handlerPointers(testpb.HelloService_ServiceDesc)
+		client := serveDesc(t,
+			WrapMethodsWithPlacement(testpb.HelloService_ServiceDesc, tc.placement, rec.unary("a"), rec.unary("b")),
+			grpc.ChainUnaryInterceptor(rec.unary("global")),
+		)
+		require.Equal(t, original, handlerPointers(testpb.HelloService_ServiceDesc), "original desc was modified")
+
+		_, err := client.HelloUnary(context.Background(), &testpb.HelloRequest{Message: "hi"})
+		require.NoError(t, err)
+		require.Equal(t, tc.expected, rec.take())
+	}
+}
+
+func TestWrapStreamsWithPlacement(t *testing.T) {
+	const prefix = "/testpb.HelloService/"
+	runStreams := func(t *testing.T, client testpb.HelloServiceClient) {
+		ctx := context.Background()
+
+		serverStream, err := client.HelloStreaming(ctx, &testpb.HelloRequest{Message: "hi"})
+		require.NoError(t, err)
+		for {
+			_, err := serverStream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(t, err)
+		}
+
+		clientStream, err := client.HelloClientStreaming(ctx)
+		require.NoError(t, err)
+		require.NoError(t, clientStream.Send(&testpb.HelloRequest{Message: "a"}))
+		require.NoError(t, clientStream.Send(&testpb.HelloRequest{Message: "b"}))
+		resp, err := clientStream.CloseAndRecv()
+		require.NoError(t, err)
+		require.Equal(t, "a,b", resp.Message)
+
+		bidiStream, err := client.HelloBidiStreaming(ctx)
+		require.NoError(t, err)
+		require.NoError(t, bidiStream.Send(&testpb.HelloRequest{Message: "hi"}))
+		resp, err = bidiStream.Recv()
+		require.NoError(t, err)
+		require.Equal(t, "hi", resp.Message)
+		require.NoError(t, bidiStream.CloseSend())
+		_, err = bidiStream.Recv()
+		require.ErrorIs(t, err, io.EOF)
+	}
+
+	expectedCalls := func(order ...string) []string {
+		var calls []string
+		for _, stream := range []string{"HelloStreaming:false:true", "HelloClientStreaming:true:false", "HelloBidiStreaming:true:true"} {
+			name, flags, _ := strings.Cut(stream, ":")
+			for _, o := range order {
+				calls = append(calls, fmt.Sprintf("%s:%s%s:%s", o, prefix, name, flags))
+			}
+		}
+		return calls
+	}
+
+	t.Run("server interceptor", func(t *testing.T) {
+		var rec callRecorder
+		client := serveDesc(t,
+			WrapStreams(testpb.HelloService_ServiceDesc, rec.stream("a"), rec.stream("b")),
+			grpc.ChainStreamInterceptor(rec.stream("server")),
+		)
+		runStreams(t, client)
+		require.Equal(t, expectedCalls("server", "a", "b"), rec.take())
+	})
+
+	for _, tc := range []struct {
+		name      string
+		placement Placement
+		order     []string
+	}{
+		{"after global", AfterGlobalInterceptors, []string{"global", "a", "b"}},
+		{"before global", BeforeGlobalInterceptors, []string{"a", "b", "global"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var rec callRecorder
+			original := handlerPointers(testpb.HelloService_ServiceDesc)
+			client := serveDesc(t, WrapStreamsWithPlacement(
+				testpb.HelloService_ServiceDesc, tc.placement, rec.stream("global"), rec.stream("a"), rec.stream("b"),
+			))
+			require.Equal(t, original, handlerPointers(testpb.HelloService_ServiceDesc), "original desc was modified")
+			runStreams(t, client)
+			require.Equal(t, expectedCalls(tc.order...), rec.take())
+		})
+	}
+}
+
+func TestDefaultMiddleware(t *testing.T) {
+	desc := WrapMethods(testpb.HelloService_ServiceDesc, DefaultUnaryMiddleware...)
+	desc = WrapStreams(*desc, DefaultStreamMiddleware...)
+	client := serveDesc(t, desc)
+	ctx := context.Background()
+
+	_, err := client.HelloUnary(ctx, &testpb.HelloRequest{Message: "invalid"})
+	RequireStatus(t, codes.InvalidArgument, err)
+
+	serverStream, err := client.HelloStreaming(ctx, &testpb.HelloRequest{Message: "invalid"})
+	require.NoError(t, err)
+	_, err = serverStream.Recv()
+	RequireStatus(t, codes.InvalidArgument, err)
+
+	clientStream, err := client.HelloClientStreaming(ctx)
+	require.NoError(t, err)
+	require.NoError(t, clientStream.Send(&testpb.HelloRequest{Message: "valid"}))
+	require.NoError(t, clientStream.Send(&testpb.HelloRequest{Message: "invalid"}))
+	_, err = clientStream.CloseAndRecv()
+	RequireStatus(t, codes.InvalidArgument, err)
+
+	bidiStream, err := client.HelloBidiStreaming(ctx)
+	require.NoError(t, err)
+	require.NoError(t, bidiStream.Send(&testpb.HelloRequest{Message: "valid"}))
+	resp, err := bidiStream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "valid", resp.Message)
+	require.NoError(t, bidiStream.Send(&testpb.HelloRequest{Message: "invalid"}))
+	_, err = bidiStream.Recv()
+	RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func StreamMiddleware(counter *int) grpc.StreamServerInterceptor {
 	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		wrapper := &recvWrapper{stream, counter}
@@ -205,3 +394,32 @@ func (s *testServer) HelloUnary(_ context.Context, in *testpb.HelloRequest) (*te
 func (s *testServer) HelloStreaming(args *testpb.HelloRequest, stream testpb.HelloService_HelloStreamingServer) error {
 	return stream.Send(&testpb.HelloResponse{Message: args.Message})
 }
+
+func (s *testServer) HelloClientStreaming(stream testpb.HelloService_HelloClientStreamingServer) error {
+	var messages []string
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(&testpb.HelloResponse{Message: strings.Join(messages, ",")})
+		}
+		if err != nil {
+			return err
+		}
+		messages = append(messages, req.Message)
+	}
+}
+
+func (s *testServer) HelloBidiStreaming(stream testpb.HelloService_HelloBidiStreamingServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&testpb.HelloResponse{Message: req.Message}); err != nil {
+			return err
+		}
+	}
+}
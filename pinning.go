@@ -0,0 +1,119 @@
+package grpcutil
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// SPKIPin returns the pin of a certificate: the base64 encoded SHA-256 hash of
+// its DER encoded SubjectPublicKeyInfo, prefixed by "sha256/".
+//
+// The same pin is produced by:
+//
+//	openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+func SPKIPin(cert *x509.Certificate) string {
+	hash := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return "sha256/" + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// WithPinnedCerts returns a grpc.DialOption for requiring TLS that is
+// authenticated by the server presenting a certificate whose public key
+// matches one of the pins, as produced by SPKIPin. Providing several pins
+// allows keys to be rotated.
+//
+// With VerifyCA, or another mode that verifies certificate authorities such
+// as VerifyCAIgnoringHostname, the server's certificate must also be verified
+// by the certificate authority chain provided as caCerts, or the
+// system-provided chain if caCerts is empty, and a pin may match any
+// certificate of the verified chain.
+//
+// With SkipVerifyCA, certificate authorities and the hostname are not
+// verified, so a self-signed certificate can be trusted, and a pin must match
+// the server's own certificate.
+func WithPinnedCerts(v verification, pins []string, caCerts ...[]byte) (grpc.DialOption, error) {
+	config, err := pinnedTLSConfig(v, pins, caCerts...)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.WithTransportCredentials(credentials.NewTLS(config)), nil
+}
+
+func pinnedTLSConfig(v verification, pins []string, caCerts ...[]byte) (*tls.Config, error) {
+	if len(pins) == 0 {
+		return nil, errors.New("at least one pin is required")
+	}
+	hashes := make(map[[sha256.Size]byte]struct{}, len(pins))
+	for _, pin := range pins {
+		hash, err := parseSPKIPin(pin)
+		if err != nil {
+			return nil, err
+		}
+		hashes[hash] = struct{}{}
+	}
+
+	matches := func(certs []*x509.Certificate) bool {
+		for _, cert := range certs {
+			if _, ok := hashes[sha256.Sum256(cert.RawSubjectPublicKeyInfo)]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// The pins are checked by VerifyConnection, which unlike
+	// VerifyPeerCertificate is also called for resumed sessions.
+	if v.settings().skipCA {
+		// Without a verified chain, only the server's own certificate, whose
+		// key the handshake proves the server holds, can be trusted.
+		return tlsConfig(VerifyWithCallbacks(v, nil, func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server did not present a certificate")
+			}
+			if !matches(cs.PeerCertificates[:1]) {
+				return errors.New("server certificate does not match any pin")
+			}
+			return nil
+		}), nil), nil
+	}
+
+	var certPool *x509.CertPool
+	var err error
+	if len(caCerts) > 0 {
+		certPool, err = certPoolFromPEM(caCerts...)
+	} else {
+		certPool, err = systemCertPool()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsConfig(VerifyWithCallbacks(v, nil, func(cs tls.ConnectionState) error {
+		for _, chain := range cs.VerifiedChains {
+			if matches(chain) {
+				return nil
+			}
+		}
+		return errors.New("server certificate chain does not match any pin")
+	}), certPool), nil
+}
+
+func parseSPKIPin(pin string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(pin, "sha256/"))
+	if err != nil {
+		return hash, fmt.Errorf("invalid pin %q: %w", pin, err)
+	}
+	if len(decoded) != sha256.Size {
+		return hash, fmt.Errorf("invalid pin %q: must be a SHA-256 hash", pin)
+	}
+	copy(hash[:], decoded)
+	return hash, nil
+}
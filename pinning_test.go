@@ -0,0 +1,134 @@
+package grpcutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseCertPEM(t *testing.T, certPEM []byte) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestWithPinnedCerts(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "server")
+	serverOpt, err := WithServerCertificateBytes(certPEM, keyPEM)
+	require.NoError(t, err)
+	dial := serveHello(t, serverOpt)
+
+	leafPin := SPKIPin(parseCertPEM(t, certPEM))
+	caPin := SPKIPin(ca.cert)
+	otherPin := SPKIPin(newTestCA(t).cert)
+
+	for _, tt := range []struct {
+		name    string
+		v       verification
+		pins    []string
+		caCerts [][]byte
+		ok      bool
+	}{
+		{"leaf pin with ca", VerifyCA, []string{leafPin}, [][]byte{ca.certPEM}, true},
+		{"ca pin with ca", VerifyCA, []string{otherPin, caPin}, [][]byte{ca.certPEM}, true},
+		{"wrong pin with ca", VerifyCA, []string{otherPin}, [][]byte{ca.certPEM}, false},
+		{"leaf pin with untrusted ca", VerifyCA, []string{leafPin}, nil, false},
+		{"leaf pin without ca", SkipVerifyCA, []string{otherPin, leafPin}, nil, true},
+		{"ca pin without ca", SkipVerifyCA, []string{caPin}, nil, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			opt, err := WithPinnedCerts(tt.v, tt.pins, tt.caCerts...)
+			require.NoError(t, err)
+
+			err = sayHello(dial(opt))
+			if tt.ok {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+
+	_, err = WithPinnedCerts(VerifyCA, nil)
+	require.Error(t, err)
+	_, err = WithPinnedCerts(VerifyCA, []string{"sha256/not base64!"})
+	require.ErrorContains(t, err, "invalid pin")
+	_, err = WithPinnedCerts(VerifyCA, []string{"c2hvcnQ="})
+	require.ErrorContains(t, err, "must be a SHA-256 hash")
+}
+
+func TestPinnedCertsResumedSession(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "server")
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("x"))
+			_ = conn.Close()
+		}
+	}()
+
+	dial := func(config *tls.Config) error {
+		conn, err := tls.Dial("tcp", lis.Addr().String(), config)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		// Session tickets are received after the handshake.
+		_, err = conn.Read(make([]byte, 1))
+		return err
+	}
+
+	otherPin := SPKIPin(newTestCA(t).cert)
+	for _, tt := range []struct {
+		name string
+		v    verification
+		pin  string
+	}{
+		{"verify ca", VerifyCA, SPKIPin(ca.cert)},
+		{"ignoring hostname", VerifyCAIgnoringHostname, SPKIPin(ca.cert)},
+		{"skip verify ca", SkipVerifyCA, SPKIPin(parseCertPEM(t, certPEM))},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := tls.NewLRUClientSessionCache(1)
+			var resumed bool
+			pinnedConfig := func(pin string) *tls.Config {
+				config, err := pinnedTLSConfig(tt.v, []string{pin}, ca.certPEM)
+				require.NoError(t, err)
+				config.ServerName = "localhost"
+				config.ClientSessionCache = cache
+				verifyConnection := config.VerifyConnection
+				config.VerifyConnection = func(cs tls.ConnectionState) error {
+					resumed = cs.DidResume
+					return verifyConnection(cs)
+				}
+				return config
+			}
+
+			require.NoError(t, dial(pinnedConfig(tt.pin)))
+			require.False(t, resumed)
+
+			// A resumed session must still be checked against the pins.
+			require.ErrorContains(t, dial(pinnedConfig(otherPin)), "does not match any pin")
+			require.True(t, resumed)
+		})
+	}
+}
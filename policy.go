@@ -0,0 +1,158 @@
+package grpcutil
+
+import (
+	"context"
+
+	grpcmw "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type ruleKind int
+
+const (
+	ruleAuthenticated ruleKind = iota
+	rulePublic
+	ruleDeny
+)
+
+// MethodRule decides whether requests to a method are permitted by an
+// AuthPolicy.
+type MethodRule struct {
+	kind  ruleKind
+	check func(ctx context.Context) error
+}
+
+var (
+	// Authenticated is a MethodRule that permits any authenticated request.
+	//
+	// It is the zero value of MethodRule.
+	Authenticated = MethodRule{kind: ruleAuthenticated}
+
+	// Public is a MethodRule that permits every request without
+	// authenticating it.
+	Public = MethodRule{kind: rulePublic}
+
+	// Deny is a MethodRule that rejects every request.
+	Deny = MethodRule{kind: ruleDeny}
+)
+
+// Authorize returns a MethodRule that permits authenticated requests for
+// which check returns nil.
+//
+// The check receives the context returned by the AuthFunc and errors that are
+// not gRPC statuses are returned with the PermissionDenied status code.
+func Authorize(check func(ctx context.Context) error) MethodRule {
+	return MethodRule{kind: ruleAuthenticated, check: check}
+}
+
+// ScopedPrincipal is implemented by principals that carry scopes.
+type ScopedPrincipal interface {
+	HasScope(scope string) bool
+}
+
+// RequireScopes returns a MethodRule that permits authenticated requests
+// whose principal is a ScopedPrincipal with all of the scopes.
+func RequireScopes(scopes ...string) MethodRule {
+	return Authorize(func(ctx context.Context) error {
+		principal, ok := PrincipalFromContext[ScopedPrincipal](ctx)
+		if !ok {
+			return status.Error(codes.PermissionDenied, "principal does not have scopes")
+		}
+		for _, scope := range scopes {
+			if !principal.HasScope(scope) {
+				return status.Errorf(codes.PermissionDenied, "missing required scope %q", scope)
+			}
+		}
+		return nil
+	})
+}
+
+// AuthPolicy authenticates and authorizes requests according to a rule for
+// each method.
+//
+// Its interceptors can be installed on a server or passed to WrapMethods and
+// WrapStreams.
+type AuthPolicy struct {
+	// AuthFunc authenticates requests to methods that are not Public. If the
+	// service implements grpc_auth.ServiceAuthFuncOverride, it is used
+	// instead. If nil, requests are assumed to have been authenticated by an
+	// earlier interceptor.
+	AuthFunc grpc_auth.AuthFunc
+
+	// Methods maps full method names, such as "/package.Service/Method", to
+	// their rule. A key of the form "/package.Service/*" applies to every
+	// method of the service that is not otherwise listed.
+	Methods map[string]MethodRule
+
+	// Default is the rule for methods that are not listed in Methods.
+	Default MethodRule
+}
+
+// Rule returns the rule that applies to the full method name.
+func (p AuthPolicy) Rule(fullMethod string) MethodRule {
+	for _, pattern := range methodPatterns(fullMethod) {
+		if rule, ok := p.Methods[pattern]; ok {
+			return rule
+		}
+	}
+	return p.Default
+}
+
+func (p AuthPolicy) authorize(ctx context.Context, srv any, fullMethod string) (context.Context, error) {
+	rule := p.Rule(fullMethod)
+	switch rule.kind {
+	case rulePublic:
+		return ctx, nil
+	case ruleDeny:
+		return nil, status.Errorf(codes.PermissionDenied, "method %s is not permitted", fullMethod)
+	}
+
+	var err error
+	if override, ok := srv.(grpc_auth.ServiceAuthFuncOverride); ok {
+		ctx, err = override.AuthFuncOverride(ctx, fullMethod)
+	} else if p.AuthFunc != nil {
+		ctx, err = p.AuthFunc(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.check != nil {
+		if err := rule.check(ctx); err != nil {
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+	}
+	return ctx, nil
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that enforces
+// the policy.
+func (p AuthPolicy) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := p.authorize(ctx, info.Server, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// enforces the policy.
+func (p AuthPolicy) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := p.authorize(stream.Context(), srv, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		wrapped := grpcmw.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,97 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+type scopedTestPrincipal []string
+
+func (p scopedTestPrincipal) HasScope(scope string) bool { return slices.Contains(p, scope) }
+
+func testPolicy(methods map[string]MethodRule) AuthPolicy {
+	return AuthPolicy{
+		AuthFunc: BearerTokenAuthFunc(StaticTokens(map[string]any{
+			"reader": scopedTestPrincipal{"read"},
+			"nobody": "nobody",
+		})),
+		Methods: methods,
+	}
+}
+
+func helloStreaming(client testpb.HelloServiceClient) error {
+	stream, err := client.HelloStreaming(context.Background(), &testpb.HelloRequest{Message: "hi"})
+	if err != nil {
+		return err
+	}
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestAuthPolicyRule(t *testing.T) {
+	policy := AuthPolicy{
+		Methods: map[string]MethodRule{
+			"/testpb.HelloService/*":          Deny,
+			"/testpb.HelloService/HelloUnary": Public,
+		},
+	}
+	require.Equal(t, rulePublic, policy.Rule("/testpb.HelloService/HelloUnary").kind)
+	require.Equal(t, ruleDeny, policy.Rule("/testpb.HelloService/HelloStreaming").kind)
+	require.Equal(t, ruleAuthenticated, policy.Rule("/other.Service/Method").kind)
+}
+
+func TestAuthPolicyInterceptors(t *testing.T) {
+	policy := testPolicy(map[string]MethodRule{
+		"/testpb.HelloService/HelloUnary":     Public,
+		"/testpb.HelloService/HelloStreaming": RequireScopes("read"),
+	})
+	dial := serveHello(t,
+		grpc.ChainUnaryInterceptor(policy.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(policy.StreamServerInterceptor()),
+	)
+
+	anonymous := dial(grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, sayHello(anonymous))
+	RequireStatus(t, codes.Unauthenticated, helloStreaming(anonymous))
+
+	nobody := dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("nobody"))
+	RequireStatus(t, codes.PermissionDenied, helloStreaming(nobody))
+
+	reader := dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("reader"))
+	require.NoError(t, helloStreaming(reader))
+}
+
+func TestAuthPolicyWrapped(t *testing.T) {
+	policy := testPolicy(map[string]MethodRule{
+		"/testpb.HelloService/HelloUnary":     Deny,
+		"/testpb.HelloService/HelloStreaming": Authenticated,
+	})
+
+	wrapped := WrapMethods(testpb.HelloService_ServiceDesc, policy.UnaryServerInterceptor())
+	wrapped = WrapStreams(*wrapped, policy.StreamServerInterceptor())
+	_, dial := serve(t, func(s *grpc.Server) {
+		s.RegisterService(wrapped, &testServer{})
+	})
+	conn := dial(grpc.WithTransportCredentials(insecure.NewCredentials()), WithInsecureBearerToken("nobody"))
+
+	client := testpb.NewHelloServiceClient(conn)
+	RequireStatus(t, codes.PermissionDenied, sayHello(client))
+	require.NoError(t, helloStreaming(client))
+}
@@ -0,0 +1,98 @@
+// Package protovalidate provides gRPC server interceptors that validate
+// requests against the rules annotated on their message descriptors using
+// protovalidate.
+//
+// It is a separate package so that importing grpcutil does not depend on the
+// CEL runtime used to evaluate the rules.
+package protovalidate
+
+import (
+	"context"
+	"errors"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that validates
+// requests against the protovalidate rules annotated on their message
+// descriptors.
+//
+// Invalid requests are rejected with the InvalidArgument status code and an
+// errdetails.BadRequest detail listing each field violation. If validator is
+// nil, protovalidate.GlobalValidator is used.
+func UnaryServerInterceptor(validator protovalidate.Validator) grpc.UnaryServerInterceptor {
+	if validator == nil {
+		validator = protovalidate.GlobalValidator
+	}
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validateMessage(validator, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that
+// validates every message received on a stream against the protovalidate
+// rules annotated on its message descriptor.
+//
+// Invalid messages are rejected with the InvalidArgument status code and an
+// errdetails.BadRequest detail listing each field violation. If validator is
+// nil, protovalidate.GlobalValidator is used.
+func StreamServerInterceptor(validator protovalidate.Validator) grpc.StreamServerInterceptor {
+	if validator == nil {
+		validator = protovalidate.GlobalValidator
+	}
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ServerStream: stream, validator: validator})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+	validator protovalidate.Validator
+}
+
+func (s *validatingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateMessage(s.validator, m)
+}
+
+func validateMessage(validator protovalidate.Validator, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := validator.Validate(msg)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *protovalidate.ValidationError
+	if !errors.As(err, &validationErr) {
+		return status.Errorf(codes.Internal, "failed to validate request: %s", err)
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, violation := range validationErr.Violations {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       protovalidate.FieldPathString(violation.Proto.GetField()),
+			Description: violation.Proto.GetMessage(),
+			Reason:      violation.Proto.GetRuleId(),
+		})
+	}
+
+	st, detailErr := status.New(codes.InvalidArgument, validationErr.Error()).WithDetails(badRequest)
+	if detailErr != nil {
+		return status.Error(codes.InvalidArgument, validationErr.Error())
+	}
+	return st.Err()
+}
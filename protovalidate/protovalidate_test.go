@@ -0,0 +1,108 @@
+package protovalidate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/grpcutil"
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+type validatedServer struct {
+	testpb.UnimplementedValidatedServiceServer
+}
+
+func (validatedServer) ValidatedUnary(_ context.Context, req *testpb.ValidatedRequest) (*testpb.HelloResponse, error) {
+	return &testpb.HelloResponse{Message: req.Name}, nil
+}
+
+func (validatedServer) ValidatedBidiStreaming(stream testpb.ValidatedService_ValidatedBidiStreamingServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(&testpb.HelloResponse{Message: req.Name}); err != nil {
+			return err
+		}
+	}
+}
+
+func serve(t *testing.T, opts ...grpc.ServerOption) testpb.ValidatedServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	testpb.RegisterValidatedServiceServer(s, validatedServer{})
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///localhost", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return testpb.NewValidatedServiceClient(conn)
+}
+
+func requireViolation(t *testing.T, err error) {
+	t.Helper()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	st, _ := status.FromError(err)
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	require.Len(t, badRequest.FieldViolations, 1)
+	require.Equal(t, "name", badRequest.FieldViolations[0].Field)
+	require.Equal(t, "string.min_len", badRequest.FieldViolations[0].Reason)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	client := serve(t, grpc.UnaryInterceptor(UnaryServerInterceptor(nil)))
+
+	resp, err := client.ValidatedUnary(context.Background(), &testpb.ValidatedRequest{Name: "alice"})
+	require.NoError(t, err)
+	require.Equal(t, "alice", resp.Message)
+
+	_, err = client.ValidatedUnary(context.Background(), &testpb.ValidatedRequest{Name: "al"})
+	requireViolation(t, err)
+
+	// Messages without rules and non-proto requests are passed through.
+	interceptor := UnaryServerInterceptor(nil)
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+	_, err = interceptor(context.Background(), &testpb.HelloRequest{}, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	_, err = interceptor(context.Background(), "not a message", &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client := serve(t, grpc.StreamInterceptor(StreamServerInterceptor(nil)))
+
+	stream, err := client.ValidatedBidiStreaming(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&testpb.ValidatedRequest{Name: "alice"}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "alice", resp.Message)
+
+	require.NoError(t, stream.Send(&testpb.ValidatedRequest{Name: "al"}))
+	_, err = stream.Recv()
+	requireViolation(t, err)
+}
@@ -1,12 +1,42 @@
 package grpcutil
 
 import (
+	"strings"
+
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/reflection"
 	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
 	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
+type reflectionConfig struct {
+	visible ServiceMatcher
+}
+
+// ReflectionOption configures the reflection services registered through
+// NewAuthlessReflectionInterceptor.
+type ReflectionOption func(*reflectionConfig)
+
+// WithReflectionFilter only advertises the services matched by visible.
+//
+// Other services are omitted from ListServices responses, removed from file
+// descriptor responses, and looking up their symbols fails with NOT_FOUND.
+func WithReflectionFilter(visible ServiceMatcher) ReflectionOption {
+	return func(c *reflectionConfig) { c.visible = visible }
+}
+
+// MatchServicesExcept returns a ServiceMatcher for every service other than
+// the provided service names.
+func MatchServicesExcept(serviceNames ...string) ServiceMatcher {
+	match := MatchServices(serviceNames...)
+	return func(serviceName string) bool {
+		return !match(serviceName)
+	}
+}
+
 // NewAuthlessReflectionInterceptor creates a proxy GRPCServer which automatically converts
 // ServerReflectionServer instances to ones that skip grpc auth middleware.
 //
@@ -14,28 +44,36 @@ import (
 // reflection.Register(srv)
 // to:
 // reflection.Register(grpcutil.NewAuthlessReflectionInterceptor(srv))
-func NewAuthlessReflectionInterceptor(srv reflection.GRPCServer) reflection.GRPCServer {
-	return interceptingRegistrar{srv}
-}
-
-type interceptingRegistrar struct {
-	delegate reflection.GRPCServer
-}
+func NewAuthlessReflectionInterceptor(srv reflection.GRPCServer, opts ...ReflectionOption) reflection.GRPCServer {
+	var config reflectionConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.visible == nil {
+		return NewInterceptingRegistrar(srv, AuthlessReflectionTransform)
+	}
 
-func (ir interceptingRegistrar) GetServiceInfo() map[string]grpc.ServiceInfo {
-	return ir.delegate.GetServiceInfo()
+	filter := reflectionFilter{config.visible}
+	return filteringRegistrar{
+		GRPCServer: NewInterceptingRegistrar(srv, filter.transform, AuthlessReflectionTransform),
+		filter:     filter,
+	}
 }
 
-func (ir interceptingRegistrar) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
-	reflectionSrvv1, ok := impl.(rpbv1.ServerReflectionServer)
-	if ok {
-		ir.delegate.RegisterService(desc, &authlessReflectionV1{ServerReflectionServer: reflectionSrvv1})
+// AuthlessReflectionTransform is a ServiceTransform that converts
+// ServerReflectionServer instances to ones that skip grpc auth middleware.
+//
+// Other services are returned unchanged.
+func AuthlessReflectionTransform(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+	if reflectionSrvv1, ok := impl.(rpbv1.ServerReflectionServer); ok {
+		return desc, &authlessReflectionV1{ServerReflectionServer: reflectionSrvv1}
 	}
 
-	reflectionSrvv1alpha, ok := impl.(grpc_reflection_v1alpha.ServerReflectionServer)
-	if ok {
-		ir.delegate.RegisterService(desc, &authlessReflectionV1Alpha{ServerReflectionServer: reflectionSrvv1alpha})
+	if reflectionSrvv1alpha, ok := impl.(grpc_reflection_v1alpha.ServerReflectionServer); ok {
+		return desc, &authlessReflectionV1Alpha{ServerReflectionServer: reflectionSrvv1alpha}
 	}
+
+	return desc, impl
 }
 
 type authlessReflectionV1 struct {
@@ -49,3 +87,162 @@ type authlessReflectionV1Alpha struct {
 
 	grpc_reflection_v1alpha.ServerReflectionServer
 }
+
+// filteringRegistrar omits the services hidden by the filter from
+// GetServiceInfo, which the reflection service uses to list services.
+type filteringRegistrar struct {
+	reflection.GRPCServer
+	filter reflectionFilter
+}
+
+func (fr filteringRegistrar) GetServiceInfo() map[string]grpc.ServiceInfo {
+	info := fr.GRPCServer.GetServiceInfo()
+	filtered := make(map[string]grpc.ServiceInfo, len(info))
+	for name, svcInfo := range info {
+		if fr.filter.visible(name) {
+			filtered[name] = svcInfo
+		}
+	}
+	return filtered
+}
+
+type reflectionFilter struct {
+	visible ServiceMatcher
+}
+
+// transform is a ServiceTransform that converts ServerReflectionServer
+// instances to ones that remove hidden services from their responses.
+func (f reflectionFilter) transform(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+	if reflectionSrvv1, ok := impl.(rpbv1.ServerReflectionServer); ok {
+		return desc, &filteredReflectionV1{reflectionSrvv1, f}
+	}
+
+	if reflectionSrvv1alpha, ok := impl.(grpc_reflection_v1alpha.ServerReflectionServer); ok {
+		return desc, &filteredReflectionV1Alpha{reflectionSrvv1alpha, f}
+	}
+
+	return desc, impl
+}
+
+// filterResponse removes hidden services from a reflection response.
+func (f reflectionFilter) filterResponse(resp *rpbv1.ServerReflectionResponse) (*rpbv1.ServerReflectionResponse, error) {
+	switch r := resp.MessageResponse.(type) {
+	case *rpbv1.ServerReflectionResponse_ListServicesResponse:
+		var services []*rpbv1.ServiceResponse
+		for _, svc := range r.ListServicesResponse.GetService() {
+			if f.visible(svc.GetName()) {
+				services = append(services, svc)
+			}
+		}
+		r.ListServicesResponse.Service = services
+
+	case *rpbv1.ServerReflectionResponse_FileDescriptorResponse:
+		symbol := resp.GetOriginalRequest().GetFileContainingSymbol()
+		files := r.FileDescriptorResponse.GetFileDescriptorProto()
+		for i, file := range files {
+			var fdp descriptorpb.FileDescriptorProto
+			if err := proto.Unmarshal(file, &fdp); err != nil {
+				return nil, err
+			}
+
+			var services []*descriptorpb.ServiceDescriptorProto
+			for _, svc := range fdp.GetService() {
+				fullName := svc.GetName()
+				if fdp.GetPackage() != "" {
+					fullName = fdp.GetPackage() + "." + fullName
+				}
+				if f.visible(fullName) {
+					services = append(services, svc)
+					continue
+				}
+				if i == 0 && (symbol == fullName || strings.HasPrefix(symbol, fullName+".")) {
+					return &rpbv1.ServerReflectionResponse{
+						ValidHost:       resp.GetValidHost(),
+						OriginalRequest: resp.GetOriginalRequest(),
+						MessageResponse: &rpbv1.ServerReflectionResponse_ErrorResponse{
+							ErrorResponse: &rpbv1.ErrorResponse{
+								ErrorCode:    int32(codes.NotFound),
+								ErrorMessage: "symbol not found: " + symbol,
+							},
+						},
+					}, nil
+				}
+			}
+			if len(services) == len(fdp.GetService()) {
+				continue
+			}
+
+			fdp.Service = services
+			filtered, err := proto.Marshal(&fdp)
+			if err != nil {
+				return nil, err
+			}
+			files[i] = filtered
+		}
+	}
+	return resp, nil
+}
+
+type filteredReflectionV1 struct {
+	rpbv1.ServerReflectionServer
+	filter reflectionFilter
+}
+
+func (s *filteredReflectionV1) ServerReflectionInfo(stream rpbv1.ServerReflection_ServerReflectionInfoServer) error {
+	return s.ServerReflectionServer.ServerReflectionInfo(&filteredReflectionStreamV1{stream, s.filter})
+}
+
+type filteredReflectionStreamV1 struct {
+	rpbv1.ServerReflection_ServerReflectionInfoServer
+	filter reflectionFilter
+}
+
+func (s *filteredReflectionStreamV1) Send(resp *rpbv1.ServerReflectionResponse) error {
+	filtered, err := s.filter.filterResponse(resp)
+	if err != nil {
+		return err
+	}
+	return s.ServerReflection_ServerReflectionInfoServer.Send(filtered)
+}
+
+type filteredReflectionV1Alpha struct {
+	grpc_reflection_v1alpha.ServerReflectionServer
+	filter reflectionFilter
+}
+
+func (s *filteredReflectionV1Alpha) ServerReflectionInfo(stream grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoServer) error {
+	return s.ServerReflectionServer.ServerReflectionInfo(&filteredReflectionStreamV1Alpha{stream, s.filter})
+}
+
+type filteredReflectionStreamV1Alpha struct {
+	grpc_reflection_v1alpha.ServerReflection_ServerReflectionInfoServer
+	filter reflectionFilter
+}
+
+// Send converts the response to v1, which is wire compatible with v1alpha, so
+// that it can be filtered.
+func (s *filteredReflectionStreamV1Alpha) Send(resp *grpc_reflection_v1alpha.ServerReflectionResponse) error {
+	encoded, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	var v1Resp rpbv1.ServerReflectionResponse
+	if err := proto.Unmarshal(encoded, &v1Resp); err != nil {
+		return err
+	}
+
+	filtered, err := s.filter.filterResponse(&v1Resp)
+	if err != nil {
+		return err
+	}
+
+	encoded, err = proto.Marshal(filtered)
+	if err != nil {
+		return err
+	}
+	var v1alphaResp grpc_reflection_v1alpha.ServerReflectionResponse
+	if err := proto.Unmarshal(encoded, &v1alphaResp); err != nil {
+		return err
+	}
+	return s.ServerReflection_ServerReflectionInfoServer.Send(&v1alphaResp)
+}
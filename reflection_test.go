@@ -0,0 +1,98 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	rpbv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func reflectV1(t *testing.T, conn *grpc.ClientConn, req *rpbv1.ServerReflectionRequest) *rpbv1.ServerReflectionResponse {
+	t.Helper()
+	stream, err := rpbv1.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(req))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+	return resp
+}
+
+func listServicesV1(t *testing.T, conn *grpc.ClientConn) []string {
+	t.Helper()
+	resp := reflectV1(t, conn, &rpbv1.ServerReflectionRequest{
+		MessageRequest: &rpbv1.ServerReflectionRequest_ListServices{},
+	})
+	var names []string
+	for _, svc := range resp.GetListServicesResponse().GetService() {
+		names = append(names, svc.GetName())
+	}
+	return names
+}
+
+func TestNewAuthlessReflectionInterceptor(t *testing.T) {
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		testpb.RegisterHelloServiceServer(s, &testServer{})
+		reflection.Register(NewAuthlessReflectionInterceptor(s))
+	})
+
+	require.Contains(t, listServicesV1(t, conn), "testpb.HelloService")
+	RequireStatus(t, codes.Unauthenticated, sayHello(testpb.NewHelloServiceClient(conn)))
+}
+
+func TestNewAuthlessReflectionInterceptorFiltered(t *testing.T) {
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		testpb.RegisterHelloServiceServer(s, &testServer{})
+		healthpb.RegisterHealthServer(s, health.NewServer())
+		reflection.Register(NewAuthlessReflectionInterceptor(s,
+			WithReflectionFilter(MatchServicesExcept("testpb.HelloService")),
+		))
+	})
+
+	services := listServicesV1(t, conn)
+	require.Contains(t, services, "grpc.health.v1.Health")
+	require.NotContains(t, services, "testpb.HelloService")
+
+	for _, symbol := range []string{"testpb.HelloService", "testpb.HelloService.HelloUnary"} {
+		resp := reflectV1(t, conn, &rpbv1.ServerReflectionRequest{
+			MessageRequest: &rpbv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+		})
+		require.Equal(t, int32(codes.NotFound), resp.GetErrorResponse().GetErrorCode(), symbol)
+	}
+
+	resp := reflectV1(t, conn, &rpbv1.ServerReflectionRequest{
+		MessageRequest: &rpbv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "testpb.HelloRequest"},
+	})
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	require.NotEmpty(t, files)
+	var fdp descriptorpb.FileDescriptorProto
+	require.NoError(t, proto.Unmarshal(files[0], &fdp))
+	require.Equal(t, "test.proto", fdp.GetName())
+	require.Empty(t, fdp.GetService())
+	require.NotEmpty(t, fdp.GetMessageType())
+
+	resp = reflectV1(t, conn, &rpbv1.ServerReflectionRequest{
+		MessageRequest: &rpbv1.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "grpc.health.v1.Health"},
+	})
+	require.NotEmpty(t, resp.GetFileDescriptorResponse().GetFileDescriptorProto())
+
+	stream, err := grpc_reflection_v1alpha.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&grpc_reflection_v1alpha.ServerReflectionRequest{
+		MessageRequest: &grpc_reflection_v1alpha.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: "testpb.HelloService"},
+	}))
+	alphaResp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.NotFound), alphaResp.GetErrorResponse().GetErrorCode())
+}
@@ -0,0 +1,186 @@
+package grpcutil
+
+import (
+	"context"
+	"reflect"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// ServiceTransform modifies a service before it is registered with a server.
+//
+// It returns the service description and implementation to register, which
+// may be the ones provided if the transform does not apply.
+type ServiceTransform func(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any)
+
+// NewInterceptingRegistrar creates a proxy GRPCServer which applies the
+// transforms, in order, to every service registered through it before
+// registering the result with the delegate.
+//
+// change:
+// testpb.RegisterHelloServiceServer(srv, impl)
+// to:
+// testpb.RegisterHelloServiceServer(grpcutil.NewInterceptingRegistrar(srv, transforms...), impl)
+func NewInterceptingRegistrar(delegate grpc.ServiceRegistrar, transforms ...ServiceTransform) reflection.GRPCServer {
+	return interceptingRegistrar{delegate: delegate, transforms: transforms}
+}
+
+type interceptingRegistrar struct {
+	delegate   grpc.ServiceRegistrar
+	transforms []ServiceTransform
+}
+
+func (ir interceptingRegistrar) GetServiceInfo() map[string]grpc.ServiceInfo {
+	if infoProvider, ok := ir.delegate.(interface {
+		GetServiceInfo() map[string]grpc.ServiceInfo
+	}); ok {
+		return infoProvider.GetServiceInfo()
+	}
+	return nil
+}
+
+func (ir interceptingRegistrar) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	for _, transform := range ir.transforms {
+		desc, impl = transform(desc, impl)
+	}
+	ir.delegate.RegisterService(desc, impl)
+}
+
+// ServiceMatcher reports whether a service name, such as "package.Service",
+// matches.
+type ServiceMatcher func(serviceName string) bool
+
+// MatchServices returns a ServiceMatcher for the provided service names.
+func MatchServices(serviceNames ...string) ServiceMatcher {
+	names := make(map[string]struct{}, len(serviceNames))
+	for _, name := range serviceNames {
+		names[name] = struct{}{}
+	}
+	return func(serviceName string) bool {
+		_, ok := names[serviceName]
+		return ok
+	}
+}
+
+// ForServices returns a ServiceTransform that applies the transforms, in
+// order, only to services matched by match.
+func ForServices(match ServiceMatcher, transforms ...ServiceTransform) ServiceTransform {
+	return func(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+		if !match(desc.ServiceName) {
+			return desc, impl
+		}
+		for _, transform := range transforms {
+			desc, impl = transform(desc, impl)
+		}
+		return desc, impl
+	}
+}
+
+// WrapMethodsTransform returns a ServiceTransform that wraps all
+// non-streaming endpoints with the given list of interceptors using
+// WrapMethods, leaving the registered descriptor unchanged.
+func WrapMethodsTransform(interceptors ...grpc.UnaryServerInterceptor) ServiceTransform {
+	return func(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+		return WrapMethods(*desc, interceptors...), impl
+	}
+}
+
+// WrapStreamsTransform returns a ServiceTransform that wraps all streaming
+// endpoints with the given list of interceptors using WrapStreams, leaving
+// the registered descriptor unchanged.
+func WrapStreamsTransform(interceptors ...grpc.StreamServerInterceptor) ServiceTransform {
+	return func(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+		return WrapStreams(*desc, interceptors...), impl
+	}
+}
+
+// IgnoreAuthTransform is a ServiceTransform that makes a service ignore any
+// auth requirements set by the gRPC community auth middleware, as if its
+// implementation embedded IgnoreAuthMixin.
+func IgnoreAuthTransform(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+	return AuthlessTransform(AnyPeer)(desc, impl)
+}
+
+// AuthlessTransform returns a ServiceTransform that makes a service ignore any
+// auth requirements set by the gRPC community auth middleware.
+//
+// With LocalPeersOnly, requests from peers that are not connected over a
+// loopback address or a unix socket are rejected instead, both by the auth
+// middleware and, for servers without it, by the service's handlers.
+func AuthlessTransform(p peerRestriction) ServiceTransform {
+	return func(desc *grpc.ServiceDesc, impl any) (*grpc.ServiceDesc, any) {
+		if impl == nil || desc.HandlerType == nil {
+			return desc, impl
+		}
+		// Leave mismatched implementations for the server to report.
+		if !reflect.TypeOf(impl).Implements(reflect.TypeOf(desc.HandlerType).Elem()) {
+			return desc, impl
+		}
+
+		wrapped := *desc
+		// The wrapper only implements the auth override, so the handlers
+		// below unwrap it before calling the original handlers.
+		wrapped.HandlerType = (*any)(nil)
+
+		wrapped.Methods = make([]grpc.MethodDesc, len(desc.Methods))
+		for i, m := range desc.Methods {
+			handler := m.Handler
+			wrapped.Methods[i] = grpc.MethodDesc{
+				MethodName: m.MethodName,
+				Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+					if interceptor == nil {
+						if _, err := p.authorize(ctx); err != nil {
+							return nil, err
+						}
+						return handler(srv.(*authlessService).impl, ctx, dec, nil)
+					}
+					// Check the peer after the server interceptors, as
+					// for streams, and report the wrapper to them rather
+					// than the unwrapped implementation the original
+					// handler passes along.
+					return handler(srv.(*authlessService).impl, ctx, dec, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+						withWrapper := *info
+						withWrapper.Server = srv
+						return interceptor(ctx, req, &withWrapper, func(ctx context.Context, req any) (any, error) {
+							if _, err := p.authorize(ctx); err != nil {
+								return nil, err
+							}
+							return next(ctx, req)
+						})
+					})
+				},
+			}
+		}
+
+		wrapped.Streams = make([]grpc.StreamDesc, len(desc.Streams))
+		for i, s := range desc.Streams {
+			handler := s.Handler
+			wrapped.Streams[i] = grpc.StreamDesc{
+				StreamName:    s.StreamName,
+				ClientStreams: s.ClientStreams,
+				ServerStreams: s.ServerStreams,
+				Handler: func(srv interface{}, stream grpc.ServerStream) error {
+					if _, err := p.authorize(stream.Context()); err != nil {
+						return err
+					}
+					return handler(srv.(*authlessService).impl, stream)
+				},
+			}
+		}
+
+		return &wrapped, &authlessService{impl: impl, peers: p}
+	}
+}
+
+type authlessService struct {
+	impl  any
+	peers peerRestriction
+}
+
+var _ grpc_auth.ServiceAuthFuncOverride = (*authlessService)(nil)
+
+func (s *authlessService) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
+	return s.peers.authorize(ctx)
+}
@@ -0,0 +1,78 @@
+package grpcutil
+
+import (
+	"testing"
+
+	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+// serveAuthenticated starts a server that requires a bearer token for every
+// request, registers services using register, and returns a connection to it.
+func serveAuthenticated(t *testing.T, register func(s *grpc.Server)) *grpc.ClientConn {
+	t.Helper()
+	authFunc := BearerTokenAuthFunc(StaticTokens(map[string]any{"secret": "alice"}))
+	_, dial := serve(t, register,
+		grpc.ChainUnaryInterceptor(grpc_auth.UnaryServerInterceptor(authFunc)),
+		grpc.ChainStreamInterceptor(grpc_auth.StreamServerInterceptor(authFunc)),
+	)
+	return dial(grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func TestInterceptingRegistrar(t *testing.T) {
+	var rec callRecorder
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		registrar := NewInterceptingRegistrar(s,
+			ForServices(MatchServices("testpb.HelloService"), IgnoreAuthTransform),
+			ForServices(MatchServices("other.Service"), WrapMethodsTransform(rec.unary("other"))),
+			WrapMethodsTransform(rec.unary("unary")),
+			WrapStreamsTransform(rec.stream("stream")),
+		)
+		testpb.RegisterHelloServiceServer(registrar, &testServer{})
+		require.Contains(t, registrar.GetServiceInfo(), "testpb.HelloService")
+	})
+
+	client := testpb.NewHelloServiceClient(conn)
+	require.NoError(t, sayHello(client))
+	require.NoError(t, helloStreaming(client))
+	require.Equal(t, []string{"unary", "stream:/testpb.HelloService/HelloStreaming:false:true"}, rec.take())
+}
+
+func TestInterceptingRegistrarRegisteredTwice(t *testing.T) {
+	// Registering a generated descriptor through the same transforms on
+	// several servers must not wrap its handlers more than once.
+	var rec callRecorder
+	transforms := []ServiceTransform{
+		WrapMethodsTransform(rec.unary("unary")),
+		WrapStreamsTransform(rec.stream("stream")),
+		IgnoreAuthTransform,
+	}
+	original := handlerPointers(testpb.HelloService_ServiceDesc)
+	for range 2 {
+		conn := serveAuthenticated(t, func(s *grpc.Server) {
+			testpb.RegisterHelloServiceServer(NewInterceptingRegistrar(s, transforms...), &testServer{})
+		})
+
+		client := testpb.NewHelloServiceClient(conn)
+		require.NoError(t, sayHello(client))
+		require.NoError(t, helloStreaming(client))
+		require.Equal(t, []string{"unary", "stream:/testpb.HelloService/HelloStreaming:false:true"}, rec.take())
+	}
+	require.Equal(t, original, handlerPointers(testpb.HelloService_ServiceDesc), "generated desc was modified")
+}
+
+func TestInterceptingRegistrarPassthrough(t *testing.T) {
+	conn := serveAuthenticated(t, func(s *grpc.Server) {
+		registrar := NewInterceptingRegistrar(s, ForServices(MatchServices("other.Service"), IgnoreAuthTransform))
+		testpb.RegisterHelloServiceServer(registrar, &testServer{})
+	})
+
+	client := testpb.NewHelloServiceClient(conn)
+	RequireStatus(t, codes.Unauthenticated, sayHello(client))
+	RequireStatus(t, codes.Unauthenticated, helloStreaming(client))
+}
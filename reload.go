@@ -0,0 +1,149 @@
+package grpcutil
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultReloadInterval is the interval at which files on disk are checked
+// for changes when no interval has been provided via WithReloadInterval.
+const DefaultReloadInterval = 30 * time.Second
+
+type reloadConfig struct {
+	interval time.Duration
+	onError  func(error)
+	onReload func()
+}
+
+// ReloadOption configures how files on disk are watched for changes.
+type ReloadOption func(*reloadConfig)
+
+// WithReloadInterval sets how often the watched files are checked for
+// changes. An interval that is not positive disables reloading, so the files
+// are only loaded once.
+func WithReloadInterval(interval time.Duration) ReloadOption {
+	return func(c *reloadConfig) { c.interval = interval }
+}
+
+// WithReloadErrorHandler sets a function that is called when the watched
+// files fail to be loaded. The previously loaded value remains in use.
+//
+// A failure is reported once rather than on every check, until the error
+// changes or the files are loaded successfully again.
+func WithReloadErrorHandler(fn func(error)) ReloadOption {
+	return func(c *reloadConfig) { c.onError = fn }
+}
+
+// WithReloadHandler sets a function that is called after the watched files
+// have changed and were successfully reloaded.
+func WithReloadHandler(fn func()) ReloadOption {
+	return func(c *reloadConfig) { c.onReload = fn }
+}
+
+func newReloadConfig(opts []ReloadOption) reloadConfig {
+	c := reloadConfig{interval: DefaultReloadInterval}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// poll calls load every interval until the context is cancelled and hands
+// the result to apply whenever it differs from the previously loaded
+// contents.
+func (c reloadConfig) poll(ctx context.Context, last [][]byte, load func() ([][]byte, error), apply func([][]byte) error) {
+	if c.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	// lastErr is the most recently reported error, which is not reported
+	// again until it changes or loading succeeds.
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		contents, err := load()
+		if err == nil && equalContents(last, contents) {
+			lastErr = ""
+			continue
+		}
+		if err == nil {
+			err = apply(contents)
+		}
+		if err != nil {
+			if c.onError != nil && err.Error() != lastErr {
+				c.onError(err)
+			}
+			lastErr = err.Error()
+			continue
+		}
+
+		lastErr = ""
+		last = contents
+		if c.onReload != nil {
+			c.onReload()
+		}
+	}
+}
+
+func equalContents(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// reloadingTLSCreds is a credentials.TransportCredentials that performs each
+// handshake using the most recently stored tls.Config.
+type reloadingTLSCreds struct {
+	config *atomic.Pointer[tls.Config]
+}
+
+var _ credentials.TransportCredentials = reloadingTLSCreds{}
+
+func newReloadingTLSCreds(initial *tls.Config) reloadingTLSCreds {
+	c := reloadingTLSCreds{config: &atomic.Pointer[tls.Config]{}}
+	c.config.Store(initial)
+	return c
+}
+
+func (c reloadingTLSCreds) current() credentials.TransportCredentials {
+	return credentials.NewTLS(c.config.Load())
+}
+
+func (c reloadingTLSCreds) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ClientHandshake(ctx, authority, conn)
+}
+
+func (c reloadingTLSCreds) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return c.current().ServerHandshake(conn)
+}
+
+func (c reloadingTLSCreds) Info() credentials.ProtocolInfo {
+	return c.current().Info()
+}
+
+func (c reloadingTLSCreds) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (c reloadingTLSCreds) OverrideServerName(string) error {
+	return nil
+}
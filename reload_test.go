@@ -0,0 +1,325 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a PEM encoded certificate and key signed by the CA that is
+// valid for localhost as both a server and client certificate.
+func (ca *testCA) issue(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func (ca *testCA) keyPair(t *testing.T, commonName string) tls.Certificate {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, commonName)
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	return pair
+}
+
+func writeFile(t *testing.T, path string, contents []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+}
+
+// serve starts a server using the provided server options, registers
+// services using register, and returns the server along with a function that
+// dials it using the provided dial options.
+func serve(t *testing.T, register func(s *grpc.Server), opts ...grpc.ServerOption) (*grpc.Server, func(...grpc.DialOption) *grpc.ClientConn) {
+	t.Helper()
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer(opts...)
+	register(s)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	return s, func(dialOpts ...grpc.DialOption) *grpc.ClientConn {
+		dialOpts = append(dialOpts, grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return lis.Dial()
+		}))
+		conn, err := grpc.NewClient("passthrough:///localhost", dialOpts...)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+		return conn
+	}
+}
+
+// serveHello starts a HelloService using the provided server options and
+// returns a function that dials it using the provided dial options.
+func serveHello(t *testing.T, opts ...grpc.ServerOption) func(...grpc.DialOption) testpb.HelloServiceClient {
+	t.Helper()
+	_, dial := serve(t, func(s *grpc.Server) {
+		s.RegisterService(&testpb.HelloService_ServiceDesc, &testServer{})
+	}, opts...)
+	return func(dialOpts ...grpc.DialOption) testpb.HelloServiceClient {
+		return testpb.NewHelloServiceClient(dial(dialOpts...))
+	}
+}
+
+func sayHello(client testpb.HelloServiceClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.HelloUnary(ctx, &testpb.HelloRequest{Message: "hi"}, grpc.WaitForReady(false))
+	return err
+}
+
+func TestWithReloadingCustomCerts(t *testing.T) {
+	serverCA := newTestCA(t)
+	otherCA := newTestCA(t)
+	dial := serveHello(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCA.keyPair(t, "server")},
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caPath, otherCA.certPEM)
+
+	reloadErrs := make(chan error, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	opt, err := WithReloadingCustomCerts(ctx, VerifyCA, []string{caPath},
+		WithReloadInterval(10*time.Millisecond),
+		WithReloadErrorHandler(func(err error) { reloadErrs <- err }),
+	)
+	require.NoError(t, err)
+
+	client := dial(opt)
+	require.Error(t, sayHello(client), "server certificate should not be trusted")
+
+	writeFile(t, caPath, []byte("not a certificate"))
+	select {
+	case err := <-reloadErrs:
+		require.ErrorContains(t, err, "failed to append certs")
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "reload error was not reported")
+	}
+
+	writeFile(t, caPath, serverCA.certPEM)
+	require.Eventually(t, func() bool {
+		return sayHello(client) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	serverCA := newTestCA(t)
+	clientCA := newTestCA(t)
+	otherCA := newTestCA(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+	dial := serveHello(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCA.keyPair(t, "server")},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	writeFile(t, caPath, serverCA.certPEM)
+	certPEM, keyPEM := otherCA.issue(t, "client")
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	opt, err := WithClientCertificate(ctx, VerifyCA, certPath, keyPath, []string{caPath},
+		WithReloadInterval(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	client := dial(opt)
+	require.Error(t, sayHello(client), "client certificate should not be trusted")
+
+	certPEM, keyPEM = clientCA.issue(t, "client")
+	writeFile(t, keyPath, keyPEM)
+	writeFile(t, certPath, certPEM)
+	require.Eventually(t, func() bool {
+		return sayHello(client) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestWithClientCertificateEmptyCAs(t *testing.T) {
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	certPEM, keyPEM := newTestCA(t).issue(t, "client")
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+
+	// An empty directory must not fall back to the system-provided chain.
+	_, err := WithClientCertificate(context.Background(), VerifyCA, certPath, keyPath, []string{t.TempDir()})
+	require.ErrorContains(t, err, "no certificates found")
+}
+
+func TestWithClientCertificateBytes(t *testing.T) {
+	serverCA := newTestCA(t)
+	clientCA := newTestCA(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCA.cert)
+	dial := serveHello(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCA.keyPair(t, "server")},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})))
+
+	certPEM, keyPEM := clientCA.issue(t, "client")
+	opt, err := WithClientCertificateBytes(VerifyCA, certPEM, keyPEM, serverCA.certPEM)
+	require.NoError(t, err)
+	require.NoError(t, sayHello(dial(opt)))
+
+	_, err = WithClientCertificateBytes(VerifyCA, certPEM, []byte("not a key"))
+	require.Error(t, err)
+}
+
+func TestWithBearerTokenFile(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	writeFile(t, tokenPath, []byte("first\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	creds, err := newFileTokenCreds(ctx, tokenPath, true, []ReloadOption{WithReloadInterval(10 * time.Millisecond)})
+	require.NoError(t, err)
+	require.True(t, creds.RequireTransportSecurity())
+
+	md, err := creds.GetRequestMetadata(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer first", md["authorization"])
+
+	writeFile(t, tokenPath, []byte("second"))
+	require.Eventually(t, func() bool {
+		md, err := creds.GetRequestMetadata(ctx)
+		return err == nil && md["authorization"] == "Bearer second"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = WithInsecureBearerTokenFile(ctx, filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+
+	emptyPath := filepath.Join(t.TempDir(), "empty")
+	writeFile(t, emptyPath, nil)
+	_, err = WithBearerTokenFile(ctx, emptyPath)
+	require.ErrorContains(t, err, "is empty")
+}
+
+func TestReloadConfigPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// A non-positive interval disables reloading rather than panicking.
+	newReloadConfig([]ReloadOption{WithReloadInterval(0)}).poll(ctx, nil, func() ([][]byte, error) {
+		require.FailNow(t, "files should not be loaded")
+		return nil, nil
+	}, func([][]byte) error { return nil })
+
+	loadErrs := make(chan error, 100)
+	loadErrs <- errors.New("first")
+	loadErrs <- errors.New("first")
+	loadErrs <- errors.New("second")
+	loadErrs <- nil
+	loadErrs <- errors.New("second")
+
+	reported := make(chan error, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		newReloadConfig([]ReloadOption{
+			WithReloadInterval(time.Millisecond),
+			WithReloadErrorHandler(func(err error) { reported <- err }),
+		}).poll(ctx, nil, func() ([][]byte, error) {
+			select {
+			case err := <-loadErrs:
+				return nil, err
+			default:
+				cancel()
+				return nil, nil
+			}
+		}, func([][]byte) error { return nil })
+	}()
+	<-done
+	close(reported)
+
+	var messages []string
+	for err := range reported {
+		messages = append(messages, err.Error())
+	}
+	require.Equal(t, []string{"first", "second", "second"}, messages)
+}
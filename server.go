@@ -0,0 +1,210 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// DefaultListenAddress is the address a Server listens on when no address or
+// listener has been provided.
+const DefaultListenAddress = ":50051"
+
+type serverConfig struct {
+	address          string
+	listener         net.Listener
+	grpcOpts         []grpc.ServerOption
+	unary            []grpc.UnaryServerInterceptor
+	stream           []grpc.StreamServerInterceptor
+	reflection       bool
+	reflectionOpts   []ReflectionOption
+	shutdownOpts     []ShutdownOption
+	onShutdownReport func(ShutdownReport)
+}
+
+// ServerBuilderOption configures a Server.
+type ServerBuilderOption func(*serverConfig)
+
+// WithListenAddress sets the address the server listens on. Addresses of the
+// form "unix:///path/to/socket" or "unix:path/to/socket" listen on a unix
+// socket and any other address is a TCP "host:port".
+func WithListenAddress(address string) ServerBuilderOption {
+	return func(c *serverConfig) { c.address = address }
+}
+
+// WithListener sets the listener the server accepts connections from,
+// overriding the listen address.
+func WithListener(lis net.Listener) ServerBuilderOption {
+	return func(c *serverConfig) { c.listener = lis }
+}
+
+// WithServerOptions adds options passed to grpc.NewServer, such as the
+// transport credentials created by WithServerCertificate.
+func WithServerOptions(opts ...grpc.ServerOption) ServerBuilderOption {
+	return func(c *serverConfig) { c.grpcOpts = append(c.grpcOpts, opts...) }
+}
+
+// WithUnaryMiddleware adds unary interceptors that run after
+// DefaultUnaryMiddleware.
+func WithUnaryMiddleware(interceptors ...grpc.UnaryServerInterceptor) ServerBuilderOption {
+	return func(c *serverConfig) { c.unary = append(c.unary, interceptors...) }
+}
+
+// WithStreamMiddleware adds stream interceptors that run after
+// DefaultStreamMiddleware.
+func WithStreamMiddleware(interceptors ...grpc.StreamServerInterceptor) ServerBuilderOption {
+	return func(c *serverConfig) { c.stream = append(c.stream, interceptors...) }
+}
+
+// WithReflection registers the reflection service, exempt from auth
+// middleware via NewAuthlessReflectionInterceptor.
+func WithReflection(opts ...ReflectionOption) ServerBuilderOption {
+	return func(c *serverConfig) {
+		c.reflection = true
+		c.reflectionOpts = opts
+	}
+}
+
+// WithShutdownOptions configures how the server is shut down by Run.
+func WithShutdownOptions(opts ...ShutdownOption) ServerBuilderOption {
+	return func(c *serverConfig) { c.shutdownOpts = append(c.shutdownOpts, opts...) }
+}
+
+// WithShutdownReportHandler sets a function called with the report of the
+// shutdown performed by Run, such as to log RPCs that blocked it.
+func WithShutdownReportHandler(handler func(ShutdownReport)) ServerBuilderOption {
+	return func(c *serverConfig) { c.onShutdownReport = handler }
+}
+
+// Server is a gRPC server bundled with a health server, optional reflection,
+// the default middleware and graceful shutdown.
+//
+// Services registered with it are reported SERVING by its health server.
+type Server struct {
+	config   serverConfig
+	grpc     *grpc.Server
+	health   *AuthlessHealthServer
+	shutdown *ShutdownCoordinator
+}
+
+var _ reflection.GRPCServer = (*Server)(nil)
+
+// NewServer creates a Server configured by the options.
+func NewServer(opts ...ServerBuilderOption) *Server {
+	config := serverConfig{address: DefaultListenAddress}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	s := &Server{config: config, health: NewAuthlessHealthServer()}
+	s.shutdown = NewShutdownCoordinator(s.health, config.shutdownOpts...)
+
+	unary := append([]grpc.UnaryServerInterceptor{s.shutdown.UnaryServerInterceptor()}, DefaultUnaryMiddleware...)
+	stream := append([]grpc.StreamServerInterceptor{s.shutdown.StreamServerInterceptor()}, DefaultStreamMiddleware...)
+	grpcOpts := append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(append(unary, config.unary...)...),
+		grpc.ChainStreamInterceptor(append(stream, config.stream...)...),
+	}, config.grpcOpts...)
+	s.grpc = grpc.NewServer(grpcOpts...)
+
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	if config.reflection {
+		reflection.Register(NewAuthlessReflectionInterceptor(s.grpc, config.reflectionOpts...))
+	}
+	return s
+}
+
+// RegisterService registers a service and reports it SERVING. A HealthChecker
+// reporting to Health replaces that status at its next check if the service
+// has probes.
+func (s *Server) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	s.grpc.RegisterService(desc, impl)
+	s.health.SetServicesHealthy(desc)
+}
+
+// GetServiceInfo returns the services registered with the server.
+func (s *Server) GetServiceInfo() map[string]grpc.ServiceInfo {
+	return s.grpc.GetServiceInfo()
+}
+
+// GRPCServer returns the underlying server.
+func (s *Server) GRPCServer() *grpc.Server {
+	return s.grpc
+}
+
+// Health returns the server's health server, such as to create a
+// HealthChecker.
+func (s *Server) Health() *AuthlessHealthServer {
+	return s.health
+}
+
+// Run serves requests until the provided context is cancelled or a shutdown
+// signal is received, and then shuts the server down gracefully.
+//
+// It returns an error if the server could not listen or stopped serving
+// unexpectedly.
+func (s *Server) Run(ctx context.Context) error {
+	lis := s.config.listener
+	if lis == nil {
+		var err error
+		if lis, err = listen(s.config.address); err != nil {
+			return err
+		}
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.grpc.Serve(lis)
+	}()
+
+	ctx, stop := signal.NotifyContext(ctx, s.shutdown.signals...)
+	defer stop()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	report := s.shutdown.Shutdown(context.WithoutCancel(ctx), s.grpc)
+	if s.config.onShutdownReport != nil {
+		s.config.onShutdownReport(report)
+	}
+	return <-serveErr
+}
+
+func listen(address string) (net.Listener, error) {
+	path, ok := strings.CutPrefix(address, "unix://")
+	if !ok {
+		path, ok = strings.CutPrefix(address, "unix:")
+	}
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+
+	// Remove a socket left behind by a previous process that did not exit
+	// cleanly, which refuses connections, but not one that is still served.
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		conn, err := net.DialTimeout("unix", path, time.Second)
+		switch {
+		case err == nil:
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to listen on %s: %w", path, syscall.EADDRINUSE)
+		case errors.Is(err, syscall.ECONNREFUSED):
+			if err := os.Remove(path); err != nil {
+				return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+			}
+		}
+	}
+	return net.Listen("unix", path)
+}
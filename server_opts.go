@@ -0,0 +1,108 @@
+package grpcutil
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// WithServerCertificate returns a grpc.ServerOption for serving TLS using the
+// certificate and key provided as paths on disk.
+//
+// If any clientCAPaths are provided, clients are required to present a
+// certificate that is authenticated using the certificate authority chain at
+// those paths. If a path is a directory, all files are loaded. The paths must
+// contain at least one certificate, including when they are reloaded.
+//
+// The certificate, key and client certificate authorities are watched for
+// changes until the provided context is cancelled, and new handshakes use the
+// most recently loaded files. If reloading fails, the previous files remain in
+// use and the error is reported via WithReloadErrorHandler.
+func WithServerCertificate(ctx context.Context, certPath, keyPath string, clientCAPaths []string, opts ...ReloadOption) (grpc.ServerOption, error) {
+	load := func() ([][]byte, error) {
+		files, err := readKeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(clientCAPaths) == 0 {
+			return files, nil
+		}
+
+		caFiles, err := readCAPaths(clientCAPaths...)
+		if err != nil {
+			return nil, err
+		}
+		return append(files, caFiles...), nil
+	}
+
+	files, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	requireClientCert := len(clientCAPaths) > 0
+	config, err := serverTLSConfig(files[0], files[1], requireClientCert, files[2:]...)
+	if err != nil {
+		return nil, err
+	}
+
+	creds := newReloadingTLSCreds(config)
+	go newReloadConfig(opts).poll(ctx, files, load, func(files [][]byte) error {
+		config, err := serverTLSConfig(files[0], files[1], requireClientCert, files[2:]...)
+		if err != nil {
+			return err
+		}
+		creds.config.Store(config)
+		return nil
+	})
+
+	return grpc.Creds(creds), nil
+}
+
+// WithServerCertificateBytes returns a grpc.ServerOption for serving TLS using
+// the certificate and key provided in bytes.
+//
+// If any clientCACerts are provided, clients are required to present a
+// certificate that is authenticated using that certificate authority chain.
+func WithServerCertificateBytes(certPEM, keyPEM []byte, clientCACerts ...[]byte) (grpc.ServerOption, error) {
+	config, err := serverTLSConfig(certPEM, keyPEM, len(clientCACerts) > 0, clientCACerts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.Creds(credentials.NewTLS(config)), nil
+}
+
+// serverTLSConfig returns a tls.Config that serves the keypair and, if
+// requireClientCert is true, requires clients to present a certificate
+// authenticated by clientCACerts, which must then not be empty so that
+// missing certificate authorities cannot disable mutual TLS.
+func serverTLSConfig(certPEM, keyPEM []byte, requireClientCert bool, clientCACerts ...[]byte) (*tls.Config, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{pair},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if requireClientCert {
+		if len(clientCACerts) == 0 {
+			return nil, errors.New("no client certificate authorities were provided")
+		}
+		certPool, err := certPoolFromPEM(clientCACerts...)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientCAs = certPool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
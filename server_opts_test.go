@@ -0,0 +1,116 @@
+package grpcutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithServerCertificate(t *testing.T) {
+	serverCA := newTestCA(t)
+	otherCA := newTestCA(t)
+	clientCA := newTestCA(t)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	clientCAPath := filepath.Join(dir, "client-ca.pem")
+	certPEM, keyPEM := otherCA.issue(t, "server")
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+	writeFile(t, clientCAPath, clientCA.certPEM)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	creds, err := WithServerCertificate(ctx, certPath, keyPath, []string{clientCAPath},
+		WithReloadInterval(10*time.Millisecond),
+	)
+	require.NoError(t, err)
+	dial := serveHello(t, creds)
+
+	clientCertPEM, clientKeyPEM := clientCA.issue(t, "client")
+	opt, err := WithClientCertificateBytes(VerifyCA, clientCertPEM, clientKeyPEM, serverCA.certPEM)
+	require.NoError(t, err)
+	client := dial(opt)
+	require.Error(t, sayHello(client), "server certificate should not be trusted")
+
+	certPEM, keyPEM = serverCA.issue(t, "server")
+	writeFile(t, keyPath, keyPEM)
+	writeFile(t, certPath, certPEM)
+	require.Eventually(t, func() bool {
+		return sayHello(client) == nil
+	}, 5*time.Second, 20*time.Millisecond)
+
+	withoutClientCert, err := WithCustomCertBytes(VerifyCA, serverCA.certPEM)
+	require.NoError(t, err)
+	require.Error(t, sayHello(dial(withoutClientCert)), "client certificate should be required")
+}
+
+func TestWithServerCertificateEmptyClientCAs(t *testing.T) {
+	serverCA := newTestCA(t)
+	clientCA := newTestCA(t)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	certPEM, keyPEM := serverCA.issue(t, "server")
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// An empty directory must not disable mutual TLS.
+	clientCADir := t.TempDir()
+	_, err := WithServerCertificate(ctx, certPath, keyPath, []string{clientCADir})
+	require.ErrorContains(t, err, "no certificates found")
+
+	clientCAPath := filepath.Join(clientCADir, "ca.pem")
+	writeFile(t, clientCAPath, clientCA.certPEM)
+	reloadErrs := make(chan error, 10)
+	creds, err := WithServerCertificate(ctx, certPath, keyPath, []string{clientCADir},
+		WithReloadInterval(10*time.Millisecond),
+		WithReloadErrorHandler(func(err error) { reloadErrs <- err }),
+	)
+	require.NoError(t, err)
+	dial := serveHello(t, creds)
+
+	withoutClientCert, err := WithCustomCertBytes(VerifyCA, serverCA.certPEM)
+	require.NoError(t, err)
+	client := dial(withoutClientCert)
+	require.Error(t, sayHello(client), "client certificate should be required")
+
+	// Emptying the directory, such as during rotation, keeps the previous
+	// client certificate authorities.
+	require.NoError(t, os.Remove(clientCAPath))
+	select {
+	case err := <-reloadErrs:
+		require.ErrorContains(t, err, "no certificates found")
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "reload error was not reported")
+	}
+	require.Error(t, sayHello(client), "client certificate should still be required")
+
+	_, err = serverTLSConfig(certPEM, keyPEM, true)
+	require.Error(t, err)
+}
+
+func TestWithServerCertificateBytes(t *testing.T) {
+	serverCA := newTestCA(t)
+
+	certPEM, keyPEM := serverCA.issue(t, "server")
+	creds, err := WithServerCertificateBytes(certPEM, keyPEM)
+	require.NoError(t, err)
+	dial := serveHello(t, creds)
+
+	opt, err := WithCustomCertBytes(VerifyCA, serverCA.certPEM)
+	require.NoError(t, err)
+	require.NoError(t, sayHello(dial(opt)))
+
+	_, err = WithServerCertificateBytes(certPEM, keyPEM, []byte("not a certificate"))
+	require.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package grpcutil
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+func TestServerRun(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "grpc.sock")
+
+	// A socket left behind by a previous process is replaced.
+	stale, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	reports := make(chan ShutdownReport, 1)
+	s := NewServer(
+		WithListenAddress("unix://"+socketPath),
+		WithReflection(),
+		WithShutdownOptions(WithDrainPeriod(0)),
+		WithShutdownReportHandler(func(report ShutdownReport) { reports <- report }),
+	)
+	testpb.RegisterHelloServiceServer(s, &testServer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ran := make(chan error, 1)
+	go func() {
+		ran <- s.Run(ctx)
+	}()
+
+	conn, err := grpc.NewClient("unix://"+socketPath, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := testpb.NewHelloServiceClient(conn)
+
+	require.Eventually(t, func() bool {
+		return sayHello(client) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "testpb.HelloService"})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	require.Contains(t, listServicesV1(t, conn), "testpb.HelloService")
+
+	// The default middleware validates requests.
+	_, err = client.HelloUnary(context.Background(), &testpb.HelloRequest{Message: "invalid"})
+	RequireStatus(t, codes.InvalidArgument, err)
+
+	cancel()
+	require.NoError(t, <-ran)
+	require.Equal(t, ShutdownReport{}, <-reports)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, s.Health(), "testpb.HelloService"))
+
+	_, err = os.Stat(socketPath)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestServerRunListenError(t *testing.T) {
+	s := NewServer(WithListenAddress("unix://" + filepath.Join(t.TempDir(), "missing", "grpc.sock")))
+	require.Error(t, s.Run(context.Background()))
+}
+
+func TestServerRunSocketInUse(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "grpc.sock")
+	live, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = live.Close() })
+
+	s := NewServer(WithListenAddress("unix://" + socketPath))
+	require.ErrorIs(t, s.Run(context.Background()), syscall.EADDRINUSE)
+
+	// The socket still belongs to the live listener.
+	conn, err := net.Dial("unix", socketPath)
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
@@ -0,0 +1,194 @@
+package grpcutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultDrainPeriod is how long services are reported NOT_SERVING before
+	// the server stops accepting requests when no period has been provided.
+	DefaultDrainPeriod = 5 * time.Second
+
+	// DefaultShutdownTimeout is how long in-flight RPCs are given to complete
+	// before they are cancelled when no timeout has been provided.
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// ShutdownOption configures a ShutdownCoordinator.
+type ShutdownOption func(*ShutdownCoordinator)
+
+// WithDrainPeriod sets how long services are reported NOT_SERVING, giving
+// load balancers time to stop sending requests, before the server stops
+// accepting them.
+func WithDrainPeriod(period time.Duration) ShutdownOption {
+	return func(c *ShutdownCoordinator) { c.drainPeriod = period }
+}
+
+// WithShutdownTimeout sets how long in-flight RPCs are given to complete
+// after the drain period before the server is stopped forcefully.
+func WithShutdownTimeout(timeout time.Duration) ShutdownOption {
+	return func(c *ShutdownCoordinator) { c.timeout = timeout }
+}
+
+// WithShutdownSignals sets the signals that trigger shutdown in
+// ShutdownOnSignal. The defaults are SIGINT and SIGTERM.
+func WithShutdownSignals(signals ...os.Signal) ShutdownOption {
+	return func(c *ShutdownCoordinator) { c.signals = signals }
+}
+
+// InFlightRPC describes an RPC that was being handled.
+type InFlightRPC struct {
+	FullMethod string
+	Started    time.Time
+}
+
+// ShutdownReport describes how a server was shut down.
+type ShutdownReport struct {
+	// Forced is true if the shutdown timeout elapsed, or the context passed
+	// to Shutdown was done, and the server was stopped, cancelling the RPCs
+	// that were still in flight.
+	Forced bool
+
+	// Blocked lists the RPCs that were still in flight when the server was
+	// stopped forcefully, oldest first.
+	Blocked []InFlightRPC
+}
+
+// ShutdownCoordinator shuts down a server gracefully: it marks every service
+// of the health server NOT_SERVING, waits for the drain period, and then
+// calls GracefulStop, falling back to Stop if in-flight RPCs do not complete
+// within the shutdown timeout.
+//
+// RPCs are only tracked if its interceptors are installed on the server.
+type ShutdownCoordinator struct {
+	health      *AuthlessHealthServer
+	drainPeriod time.Duration
+	timeout     time.Duration
+	signals     []os.Signal
+
+	mu       sync.Mutex
+	nextID   uint64
+	inFlight map[uint64]InFlightRPC
+}
+
+// NewShutdownCoordinator creates a ShutdownCoordinator which reports
+// services as NOT_SERVING via health, which may be nil.
+func NewShutdownCoordinator(health *AuthlessHealthServer, opts ...ShutdownOption) *ShutdownCoordinator {
+	c := &ShutdownCoordinator{
+		health:      health,
+		drainPeriod: DefaultDrainPeriod,
+		timeout:     DefaultShutdownTimeout,
+		signals:     []os.Signal{os.Interrupt, syscall.SIGTERM},
+		inFlight:    make(map[uint64]InFlightRPC),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// UnaryServerInterceptor returns a grpc.UnaryServerInterceptor that tracks
+// in-flight RPCs.
+func (c *ShutdownCoordinator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		defer c.track(info.FullMethod)()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a grpc.StreamServerInterceptor that tracks
+// in-flight RPCs.
+func (c *ShutdownCoordinator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer c.track(info.FullMethod)()
+		return handler(srv, stream)
+	}
+}
+
+func (c *ShutdownCoordinator) track(fullMethod string) (done func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.nextID
+	c.nextID++
+	c.inFlight[id] = InFlightRPC{FullMethod: fullMethod, Started: time.Now()}
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.inFlight, id)
+	}
+}
+
+// InFlight returns the RPCs currently being handled, oldest first.
+func (c *ShutdownCoordinator) InFlight() []InFlightRPC {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	rpcs := make([]InFlightRPC, 0, len(c.inFlight))
+	for _, rpc := range c.inFlight {
+		rpcs = append(rpcs, rpc)
+	}
+	sort.Slice(rpcs, func(i, j int) bool {
+		return rpcs[i].Started.Before(rpcs[j].Started)
+	})
+	return rpcs
+}
+
+// ShutdownOnSignal blocks until the provided context is cancelled or one of
+// the shutdown signals is received, and then shuts down the server.
+func (c *ShutdownCoordinator) ShutdownOnSignal(ctx context.Context, srv *grpc.Server) ShutdownReport {
+	signalled, stop := signal.NotifyContext(ctx, c.signals...)
+	<-signalled.Done()
+	stop()
+	return c.Shutdown(context.WithoutCancel(ctx), srv)
+}
+
+// Shutdown shuts down the server, blocking until it has stopped.
+//
+// If the context is done before the server has stopped, the drain period and
+// the wait for in-flight RPCs are cut short and the server is stopped
+// forcefully.
+func (c *ShutdownCoordinator) Shutdown(ctx context.Context, srv *grpc.Server) ShutdownReport {
+	if c.health != nil {
+		// Shutdown marks every service NOT_SERVING and ignores later
+		// updates, such as those from a HealthChecker.
+		c.health.Shutdown()
+	}
+
+	drain := time.NewTimer(c.drainPeriod)
+	defer drain.Stop()
+	select {
+	case <-drain.C:
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return ShutdownReport{}
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	report := ShutdownReport{Forced: true, Blocked: c.InFlight()}
+	srv.Stop()
+	<-stopped
+	return report
+}
@@ -0,0 +1,143 @@
+package grpcutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/grpcutil/internal/testpb"
+)
+
+// blockingServer blocks unary requests with the message "block" and
+// bidirectional streams until they are cancelled.
+type blockingServer struct {
+	testServer
+}
+
+func (s *blockingServer) HelloUnary(ctx context.Context, in *testpb.HelloRequest) (*testpb.HelloResponse, error) {
+	if in.Message == "block" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return s.testServer.HelloUnary(ctx, in)
+}
+
+func (s *blockingServer) HelloBidiStreaming(stream testpb.HelloService_HelloBidiStreamingServer) error {
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
+
+func serveWithShutdown(t *testing.T, c *ShutdownCoordinator) (*grpc.Server, testpb.HelloServiceClient) {
+	t.Helper()
+	s, dial := serve(t, func(s *grpc.Server) {
+		testpb.RegisterHelloServiceServer(s, &blockingServer{})
+	},
+		grpc.UnaryInterceptor(c.UnaryServerInterceptor()),
+		grpc.StreamInterceptor(c.StreamServerInterceptor()),
+	)
+	return s, testpb.NewHelloServiceClient(dial(grpc.WithTransportCredentials(insecure.NewCredentials())))
+}
+
+func TestShutdownCoordinator(t *testing.T) {
+	health := NewAuthlessHealthServer()
+	health.SetServicesHealthy(&testpb.HelloService_ServiceDesc)
+	c := NewShutdownCoordinator(health, WithDrainPeriod(10*time.Millisecond))
+	srv, client := serveWithShutdown(t, c)
+
+	require.NoError(t, sayHello(client))
+	require.Empty(t, c.InFlight())
+
+	report := c.Shutdown(context.Background(), srv)
+	require.Equal(t, ShutdownReport{}, report)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, health, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, health, "testpb.HelloService"))
+	RequireStatus(t, codes.Unavailable, sayHello(client))
+}
+
+func TestShutdownCoordinatorForced(t *testing.T) {
+	c := NewShutdownCoordinator(nil, WithDrainPeriod(0), WithShutdownTimeout(50*time.Millisecond))
+	srv, client := serveWithShutdown(t, c)
+
+	blocked := make(chan error, 1)
+	go func() {
+		stream, err := client.HelloBidiStreaming(context.Background())
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		blocked <- err
+	}()
+	require.Eventually(t, func() bool {
+		return len(c.InFlight()) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	report := c.Shutdown(context.Background(), srv)
+	require.True(t, report.Forced)
+	require.Len(t, report.Blocked, 1)
+	require.Equal(t, "/testpb.HelloService/HelloBidiStreaming", report.Blocked[0].FullMethod)
+	require.Error(t, <-blocked)
+}
+
+func TestShutdownCoordinatorForcedUnary(t *testing.T) {
+	c := NewShutdownCoordinator(nil, WithDrainPeriod(0), WithShutdownTimeout(50*time.Millisecond))
+	srv, client := serveWithShutdown(t, c)
+
+	blocked := make(chan error, 1)
+	go func() {
+		_, err := client.HelloUnary(context.Background(), &testpb.HelloRequest{Message: "block"})
+		blocked <- err
+	}()
+	require.Eventually(t, func() bool {
+		return len(c.InFlight()) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	report := c.Shutdown(context.Background(), srv)
+	require.True(t, report.Forced)
+	require.Len(t, report.Blocked, 1)
+	require.Equal(t, "/testpb.HelloService/HelloUnary", report.Blocked[0].FullMethod)
+	require.Error(t, <-blocked)
+}
+
+func TestShutdownCoordinatorContextDone(t *testing.T) {
+	c := NewShutdownCoordinator(nil, WithDrainPeriod(time.Hour), WithShutdownTimeout(time.Hour))
+	srv, client := serveWithShutdown(t, c)
+
+	blocked := make(chan error, 1)
+	go func() {
+		_, err := client.HelloUnary(context.Background(), &testpb.HelloRequest{Message: "block"})
+		blocked <- err
+	}()
+	require.Eventually(t, func() bool {
+		return len(c.InFlight()) == 1
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// The drain period and shutdown timeout are cut short.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	report := c.Shutdown(ctx, srv)
+	require.True(t, report.Forced)
+	require.Len(t, report.Blocked, 1)
+	require.Error(t, <-blocked)
+}
+
+func TestShutdownOnSignal(t *testing.T) {
+	health := NewAuthlessHealthServer()
+	c := NewShutdownCoordinator(health, WithDrainPeriod(0))
+	srv, client := serveWithShutdown(t, c)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan ShutdownReport, 1)
+	go func() {
+		done <- c.ShutdownOnSignal(ctx, srv)
+	}()
+
+	require.NoError(t, sayHello(client))
+	cancel()
+	require.Equal(t, ShutdownReport{}, <-done)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, health, ""))
+}
@@ -0,0 +1,154 @@
+package grpcutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// TokenRefreshWindow is how long before its expiry a token is refreshed.
+const TokenRefreshWindow = 30 * time.Second
+
+// Token is a bearer token along with the time at which it expires.
+//
+// A zero Expiry indicates that the token never expires.
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// TokenSource provides bearer tokens for use by a client.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc is an adapter to allow the use of ordinary functions as a
+// TokenSource.
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token implements TokenSource by calling f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) { return f(ctx) }
+
+// WithTokenSource returns a grpc.DialOption that adds a standard HTTP Bearer
+// token provided by the TokenSource to all requests sent from a client.
+//
+// Tokens are cached until shortly before they expire and concurrent requests
+// share a single refresh. Refresh failures are returned to the caller with
+// the Unauthenticated status code.
+func WithTokenSource(source TokenSource) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(newTokenSourceCreds(source, true))
+}
+
+// WithInsecureTokenSource returns a grpc.DialOption that adds a standard HTTP
+// Bearer token provided by the TokenSource to all requests sent from an
+// insecure client.
+//
+// Must be used in conjunction with `insecure.NewCredentials()`.
+func WithInsecureTokenSource(source TokenSource) grpc.DialOption {
+	return grpc.WithPerRPCCredentials(newTokenSourceCreds(source, false))
+}
+
+type tokenRefresh struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+type tokenSourceCreds struct {
+	source                   TokenSource
+	requireTransportSecurity bool
+	now                      func() time.Time
+
+	mu         sync.Mutex
+	token      Token
+	hasToken   bool
+	refreshing *tokenRefresh
+}
+
+var _ credentials.PerRPCCredentials = (*tokenSourceCreds)(nil)
+
+func newTokenSourceCreds(source TokenSource, requireTransportSecurity bool) *tokenSourceCreds {
+	return &tokenSourceCreds{
+		source:                   source,
+		requireTransportSecurity: requireTransportSecurity,
+		now:                      time.Now,
+	}
+}
+
+func (c *tokenSourceCreds) RequireTransportSecurity() bool { return c.requireTransportSecurity }
+
+func (c *tokenSourceCreds) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	token, err := c.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token.Value}, nil
+}
+
+func (c *tokenSourceCreds) getToken(ctx context.Context) (Token, error) {
+	c.mu.Lock()
+	now := c.now()
+	if c.hasToken && !c.expiresWithin(now, TokenRefreshWindow) {
+		token := c.token
+		c.mu.Unlock()
+		return token, nil
+	}
+
+	refresh := c.refreshing
+	if refresh == nil {
+		refresh = &tokenRefresh{done: make(chan struct{})}
+		c.refreshing = refresh
+		go c.refresh(context.WithoutCancel(ctx), refresh)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return Token{}, status.FromContextError(ctx.Err()).Err()
+	case <-refresh.done:
+	}
+
+	if refresh.err == nil {
+		return refresh.token, nil
+	}
+
+	// Keep using the previous token for as long as it remains valid.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hasToken && !c.expiresWithin(c.now(), 0) {
+		return c.token, nil
+	}
+	return Token{}, refresh.err
+}
+
+func (c *tokenSourceCreds) refresh(ctx context.Context, refresh *tokenRefresh) {
+	token, err := c.source.Token(ctx)
+	if err != nil {
+		refresh.err = status.Errorf(codes.Unauthenticated, "failed to refresh token: %s", err)
+	}
+	refresh.token = token
+
+	c.mu.Lock()
+	if err == nil {
+		c.token = token
+		c.hasToken = true
+	}
+	c.refreshing = nil
+	c.mu.Unlock()
+
+	close(refresh.done)
+}
+
+// expiresWithin returns whether the cached token expires within the window.
+// The caller must hold the lock.
+func (c *tokenSourceCreds) expiresWithin(now time.Time, window time.Duration) bool {
+	if c.token.Expiry.IsZero() {
+		return false
+	}
+	return !now.Add(window).Before(c.token.Expiry)
+}
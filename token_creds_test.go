@@ -0,0 +1,90 @@
+package grpcutil
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestTokenSourceCredsCachesAndRefreshes(t *testing.T) {
+	now := time.Now()
+	var calls atomic.Int32
+	creds := newTokenSourceCreds(TokenSourceFunc(func(context.Context) (Token, error) {
+		n := calls.Add(1)
+		return Token{Value: string(rune('a' + n - 1)), Expiry: now.Add(time.Minute)}, nil
+	}), true)
+	creds.now = func() time.Time { return now }
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Bearer a", md["authorization"])
+
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Bearer a", md["authorization"], "token should be cached")
+
+	now = now.Add(time.Minute - TokenRefreshWindow)
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Bearer b", md["authorization"], "token should be refreshed before expiry")
+	require.Equal(t, int32(2), calls.Load())
+}
+
+func TestTokenSourceCredsDeduplicatesRefreshes(t *testing.T) {
+	release := make(chan struct{})
+	var calls atomic.Int32
+	creds := newTokenSourceCreds(TokenSourceFunc(func(context.Context) (Token, error) {
+		calls.Add(1)
+		<-release
+		return Token{Value: "token"}, nil
+	}), false)
+	require.False(t, creds.RequireTransportSecurity())
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			md, err := creds.GetRequestMetadata(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, "Bearer token", md["authorization"])
+		}()
+	}
+
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestTokenSourceCredsErrors(t *testing.T) {
+	now := time.Now()
+	fail := false
+	creds := newTokenSourceCreds(TokenSourceFunc(func(context.Context) (Token, error) {
+		if fail {
+			return Token{}, errors.New("token endpoint unavailable")
+		}
+		return Token{Value: "token", Expiry: now.Add(time.Minute)}, nil
+	}), true)
+	creds.now = func() time.Time { return now }
+
+	_, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+
+	fail = true
+	now = now.Add(time.Minute - time.Second)
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err, "unexpired token should be used when refresh fails")
+	require.Equal(t, "Bearer token", md["authorization"])
+
+	now = now.Add(time.Second)
+	_, err = creds.GetRequestMetadata(context.Background())
+	RequireStatus(t, codes.Unauthenticated, err)
+	require.ErrorContains(t, err, "token endpoint unavailable")
+}
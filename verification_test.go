@@ -0,0 +1,103 @@
+package grpcutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestVerificationModes(t *testing.T) {
+	ca := newTestCA(t)
+	certPEM, keyPEM := ca.issue(t, "server")
+	serverOpt, err := WithServerCertificateBytes(certPEM, keyPEM)
+	require.NoError(t, err)
+	dial := serveHello(t, serverOpt)
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caPath, ca.certPEM)
+
+	var sawChains, sawConnection bool
+	recordChains := func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		sawChains = len(verifiedChains) > 0
+		return nil
+	}
+	recordConnection := func(cs tls.ConnectionState) error {
+		sawConnection = len(cs.VerifiedChains) > 0
+		return nil
+	}
+	reject := func([][]byte, [][]*x509.Certificate) error {
+		return errors.New("rejected")
+	}
+
+	// The certificate is only valid for localhost, which is dialed unless
+	// another authority is given.
+	otherHost := grpc.WithAuthority("other.example.com")
+
+	for _, tt := range []struct {
+		name     string
+		v        verification
+		dialOpts []grpc.DialOption
+		ok       bool
+	}{
+		{"verify ca", VerifyCA, nil, true},
+		{"verify ca with other host", VerifyCA, []grpc.DialOption{otherHost}, false},
+		{"ignoring hostname", VerifyCAIgnoringHostname, []grpc.DialOption{otherHost}, true},
+		{"server name override", VerifyCAWithServerName("localhost"), nil, true},
+		{"wrong server name override", VerifyCAWithServerName("other.example.com"), nil, false},
+		{"callbacks", VerifyWithCallbacks(VerifyCA, recordChains, recordConnection), nil, true},
+		{"rejecting callback", VerifyWithCallbacks(VerifyCA, reject, nil), nil, false},
+		{"callbacks ignoring hostname", VerifyWithCallbacks(VerifyCAIgnoringHostname, recordChains, nil), []grpc.DialOption{otherHost}, true},
+		{"connection callback ignoring hostname", VerifyWithCallbacks(VerifyCAIgnoringHostname, nil, recordConnection), []grpc.DialOption{otherHost}, true},
+		{"callbacks composed", VerifyWithCallbacks(VerifyWithCallbacks(VerifyCA, recordChains, nil), reject, nil), nil, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			sawChains, sawConnection = false, false
+
+			opt, err := WithCustomCerts(tt.v, caPath)
+			require.NoError(t, err)
+
+			err = sayHello(dial(append(tt.dialOpts, opt)...))
+			if !tt.ok {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			callbacks := tt.v.settings().callbacks
+			if callbacks != nil && callbacks.verifyPeerCertificate != nil {
+				require.True(t, sawChains, "verified chains were not provided")
+			}
+			if callbacks != nil && callbacks.verifyConnection != nil {
+				require.True(t, sawConnection, "verified chains were not provided to the connection")
+			}
+		})
+	}
+
+	t.Run("untrusted ca ignoring hostname", func(t *testing.T) {
+		opt, err := WithCustomCertBytes(VerifyCAIgnoringHostname, newTestCA(t).certPEM)
+		require.NoError(t, err)
+		require.Error(t, sayHello(dial(opt)))
+	})
+
+	t.Run("system certs with callbacks", func(t *testing.T) {
+		// The test CA is not trusted by the system, so only a callback on
+		// SkipVerifyCA can accept it.
+		opt, err := WithSystemCerts(VerifyWithCallbacks(SkipVerifyCA, func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			_, err := verifyChain(rawCerts, x509.NewCertPool())
+			if err == nil {
+				return errors.New("expected an unknown authority")
+			}
+			return nil
+		}, nil))
+		require.NoError(t, err)
+		require.NoError(t, sayHello(dial(opt)))
+
+		opt, err = WithSystemCerts(VerifyWithCallbacks(SkipVerifyCA, reject, nil))
+		require.NoError(t, err)
+		require.Error(t, sayHello(dial(opt)))
+	})
+}